@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pet-project/internal/api"
+)
+
+// client calls the API of a running instance.
+type client struct {
+	baseURL string
+	actor   string
+	http    *http.Client
+}
+
+func newClient(baseURL, actor string, timeout time.Duration, conns int) *client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = conns
+	transport.MaxIdleConnsPerHost = conns
+	return &client{
+		baseURL: baseURL,
+		actor:   actor,
+		http:    &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// apiError is a response with a status other than the one expected.
+type apiError struct {
+	Status     int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// do sends body as JSON and decodes a response with status want into out,
+// which may be nil. Other statuses are returned as an *apiError.
+func (c *client) do(ctx context.Context, method, path string, body, out any, want int) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, payload)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Actor", c.actor)
+	// Reads must see the writes of the run, not a lagging replica.
+	req.Header.Set("Cache-Control", "no-cache")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		apiErr := &apiError{Status: resp.StatusCode}
+		var errResp api.ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil {
+			apiErr.Message = errResp.Error
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// doRetrying is do that waits out rate limiting, for seeding and checks
+// that have to succeed.
+func (c *client) doRetrying(ctx context.Context, method, path string, body, out any, want int) error {
+	for {
+		err := c.do(ctx, method, path, body, out, want)
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+			return err
+		}
+		wait := max(apiErr.RetryAfter, time.Second)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *client) createUser(ctx context.Context, user api.CreateUserRequest) (int64, error) {
+	var resp api.CreateUserResponse
+	err := c.doRetrying(ctx, http.MethodPost, "/users", user, &resp, http.StatusCreated)
+	return resp.ID, err
+}
+
+func (c *client) createProduct(ctx context.Context, product api.CreateProductRequest) (int64, error) {
+	var resp api.CreateProductResponse
+	err := c.doRetrying(ctx, http.MethodPost, "/products", product, &resp, http.StatusCreated)
+	return resp.ID, err
+}
+
+func (c *client) getProduct(ctx context.Context, id int64) (api.ProductResponse, error) {
+	var product api.ProductResponse
+	err := c.doRetrying(ctx, http.MethodGet, fmt.Sprintf("/products/%d", id), nil, &product, http.StatusOK)
+	return product, err
+}
+
+func (c *client) createOrder(ctx context.Context, order api.CreateOrderRequest) error {
+	return c.do(ctx, http.MethodPost, "/orders", order, nil, http.StatusCreated)
+}
+
+func (c *client) reconcileStock(ctx context.Context) (api.StockReconciliationResponse, error) {
+	var report api.StockReconciliationResponse
+	err := c.doRetrying(ctx, http.MethodGet, "/inventory/reconciliation", nil, &report, http.StatusOK)
+	return report, err
+}
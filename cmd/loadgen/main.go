@@ -0,0 +1,324 @@
+// Command loadgen measures how a running instance handles many customers
+// ordering the same few products. It seeds users and products through the
+// API, places orders from concurrent workers with products picked on a Zipf
+// distribution, so a handful of hot products get most of the demand, and
+// reports throughput, latency percentiles and how orders failed. It ends by
+// checking that no product was oversold and exits with status 1 if any was.
+//
+// Requests count against the server's rate limits like any other client;
+// raise rate_limit for POST /orders or most orders will come back 429.
+//
+//	go run ./cmd/loadgen -url http://localhost:8080 -workers 64 -orders 5000
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pet-project/internal/api"
+)
+
+type options struct {
+	url      string
+	actor    string
+	users    int
+	products int
+	stock    int
+	workers  int
+	orders   int
+	duration time.Duration
+	items    int
+	quantity int
+	skew     float64
+	timeout  time.Duration
+	seed     uint64
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.url, "url", "http://localhost:8080", "base URL of the running instance")
+	flag.StringVar(&opts.actor, "actor", "loadgen", "X-Actor sent with every request; only recorded by an instance with trust_proxy on")
+	flag.IntVar(&opts.users, "users", 100, "users to seed")
+	flag.IntVar(&opts.products, "products", 20, "products to seed")
+	flag.IntVar(&opts.stock, "stock", 200, "initial stock of every seeded product")
+	flag.IntVar(&opts.workers, "workers", 32, "orders in flight at once")
+	flag.IntVar(&opts.orders, "orders", 2000, "orders to place; 0 for no limit")
+	flag.DurationVar(&opts.duration, "duration", 0, "stop placing orders after this long; 0 for no limit")
+	flag.IntVar(&opts.items, "items", 3, "most distinct products in one order")
+	flag.IntVar(&opts.quantity, "quantity", 2, "most units of a product in one order")
+	flag.Float64Var(&opts.skew, "skew", 1.2, "Zipf exponent of product demand, above 1; higher is more skewed")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of one request")
+	flag.Uint64Var(&opts.seed, "seed", uint64(time.Now().UnixNano()), "seed of the random workload")
+	flag.Parse()
+
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	consistent, err := run(ctx, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		os.Exit(1)
+	}
+	if !consistent {
+		os.Exit(1)
+	}
+}
+
+func (o options) validate() error {
+	var errs []error
+	if o.users < 1 || o.products < 1 || o.workers < 1 {
+		errs = append(errs, errors.New("-users, -products and -workers must be at least 1"))
+	}
+	if o.stock < 0 || o.orders < 0 || o.duration < 0 {
+		errs = append(errs, errors.New("-stock, -orders and -duration cannot be negative"))
+	}
+	if o.orders == 0 && o.duration == 0 {
+		errs = append(errs, errors.New("one of -orders and -duration must be set"))
+	}
+	if o.items < 1 || o.items > o.products {
+		errs = append(errs, errors.New("-items must be between 1 and -products"))
+	}
+	if o.quantity < 1 {
+		errs = append(errs, errors.New("-quantity must be at least 1"))
+	}
+	if o.skew <= 1 {
+		errs = append(errs, errors.New("-skew must be above 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// fixture is what a run seeded: user IDs and the IDs of products from the
+// hottest to the coldest.
+type fixture struct {
+	users    []int64
+	products []int64
+}
+
+func run(ctx context.Context, opts options) (bool, error) {
+	c := newClient(strings.TrimSuffix(opts.url, "/"), opts.actor, opts.timeout, opts.workers)
+
+	started := time.Now()
+	fx, err := seed(ctx, c, opts)
+	if err != nil {
+		return false, err
+	}
+	fmt.Printf("seeded %d users and %d products with %d in stock each in %s\n\n",
+		len(fx.users), len(fx.products), opts.stock, time.Since(started).Round(time.Millisecond))
+
+	res := placeOrders(ctx, c, fx, opts)
+	res.print(os.Stdout, fx, opts)
+
+	// The check runs even when the load was interrupted.
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	return checkStock(checkCtx, c, fx, res, opts, os.Stdout)
+}
+
+// seed creates the users and products of a run. Names and SKUs carry the
+// start time so runs against the same database do not collide.
+func seed(ctx context.Context, c *client, opts options) (fixture, error) {
+	runID := time.Now().Unix()
+	fx := fixture{users: make([]int64, opts.users), products: make([]int64, opts.products)}
+
+	jobs := make(chan func() error)
+	errs := make(chan error, opts.workers)
+	var wg sync.WaitGroup
+	for range opts.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := job(); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range fx.users {
+		jobs <- func() (err error) {
+			fx.users[i], err = c.createUser(ctx, api.CreateUserRequest{
+				FirstName: "Load",
+				LastName:  fmt.Sprintf("Gen %d-%d", runID, i),
+				Age:       18 + i%60,
+				Password:  "loadgen-password",
+			})
+			if err != nil {
+				return fmt.Errorf("failed to seed user %d: %w", i, err)
+			}
+			return nil
+		}
+	}
+	for i := range fx.products {
+		jobs <- func() (err error) {
+			fx.products[i], err = c.createProduct(ctx, api.CreateProductRequest{
+				SKU:         fmt.Sprintf("loadgen-%d-%d", runID, i),
+				Description: fmt.Sprintf("Loadgen product %d of run %d", i, runID),
+				Tags:        []string{"loadgen"},
+				Quantity:    opts.stock,
+				Price:       float64(1 + i%20),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to seed product %d: %w", i, err)
+			}
+			return nil
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return fixture{}, err
+	default:
+		return fx, nil
+	}
+}
+
+// outcome is how an order request ended.
+type outcome int
+
+const (
+	placed outcome = iota
+	stockOut
+	conflict
+	rateLimited
+	failed
+	outcomes
+)
+
+var outcomeNames = [outcomes]string{"placed", "stock-outs", "other conflicts", "rate-limited", "errors"}
+
+func classify(err error) outcome {
+	var apiErr *apiError
+	switch {
+	case err == nil:
+		return placed
+	case !errors.As(err, &apiErr):
+		return failed
+	case apiErr.Status == http.StatusConflict && strings.Contains(apiErr.Message, "not enough stock"):
+		return stockOut
+	case apiErr.Status == http.StatusConflict:
+		return conflict
+	case apiErr.Status == http.StatusTooManyRequests:
+		return rateLimited
+	default:
+		return failed
+	}
+}
+
+// workerResult is what one worker saw; workers keep their own so they
+// never contend on anything but the server. Per product, sold counts units
+// of orders the server placed and unconfirmed units of orders that got no
+// answer, which may or may not have been placed.
+type workerResult struct {
+	latencies   []time.Duration
+	counts      [outcomes]int
+	requested   []int
+	sold        []int
+	unconfirmed []int
+	errors      []string
+}
+
+func placeOrders(ctx context.Context, c *client, fx fixture, opts options) result {
+	if opts.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
+	}
+
+	var (
+		issued  atomic.Int64
+		wg      sync.WaitGroup
+		workers = make([]workerResult, opts.workers)
+	)
+	started := time.Now()
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewPCG(opts.seed, uint64(w)))
+			zipf := rand.NewZipf(r, opts.skew, 1, uint64(len(fx.products)-1))
+			res := &workers[w]
+			res.requested = make([]int, len(fx.products))
+			res.sold = make([]int, len(fx.products))
+			res.unconfirmed = make([]int, len(fx.products))
+
+			for ctx.Err() == nil {
+				if opts.orders > 0 && issued.Add(1) > int64(opts.orders) {
+					return
+				}
+
+				order, picked := newOrder(r, zipf, fx, opts)
+				begin := time.Now()
+				err := c.createOrder(ctx, order)
+				var apiErr *apiError
+				answered := err == nil || errors.As(err, &apiErr)
+				if !answered {
+					for i, item := range order.Items {
+						res.unconfirmed[picked[i]] += item.Quantity
+					}
+				}
+				if !answered && ctx.Err() != nil {
+					// Cut off by the end of the run rather than failed.
+					return
+				}
+				res.latencies = append(res.latencies, time.Since(begin))
+
+				kind := classify(err)
+				res.counts[kind]++
+				if kind == failed && len(res.errors) < sampleErrors {
+					res.errors = append(res.errors, err.Error())
+				}
+				for i, item := range order.Items {
+					res.requested[picked[i]] += item.Quantity
+					if kind == placed {
+						res.sold[picked[i]] += item.Quantity
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return merge(workers, len(fx.products), time.Since(started))
+}
+
+// newOrder returns an order of distinct products drawn from the Zipf
+// distribution, with the fixture index of each item's product. With steep
+// skew the draws keep hitting the same products, so an order may end up
+// with fewer lines than it was meant to.
+func newOrder(r *rand.Rand, zipf *rand.Zipf, fx fixture, opts options) (api.CreateOrderRequest, []int) {
+	order := api.CreateOrderRequest{UserID: fx.users[r.IntN(len(fx.users))]}
+	lines := 1 + r.IntN(opts.items)
+	picked := make([]int, 0, lines)
+	seen := make(map[int]bool, lines)
+	for draws := 0; len(picked) < lines && draws < 10*lines; draws++ {
+		i := int(zipf.Uint64())
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		picked = append(picked, i)
+		order.Items = append(order.Items, api.OrderItemRequest{ProductID: fx.products[i], Quantity: 1 + r.IntN(opts.quantity)})
+	}
+	return order, picked
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// result is what the workers saw together.
+type result struct {
+	elapsed     time.Duration
+	latencies   []time.Duration
+	counts      [outcomes]int
+	requested   []int
+	sold        []int
+	unconfirmed []int
+	errors      []string
+}
+
+func merge(workers []workerResult, products int, elapsed time.Duration) result {
+	res := result{
+		elapsed:     elapsed,
+		requested:   make([]int, products),
+		sold:        make([]int, products),
+		unconfirmed: make([]int, products),
+	}
+	for _, w := range workers {
+		res.latencies = append(res.latencies, w.latencies...)
+		for kind, n := range w.counts {
+			res.counts[kind] += n
+		}
+		for i := range w.requested {
+			res.requested[i] += w.requested[i]
+			res.sold[i] += w.sold[i]
+			res.unconfirmed[i] += w.unconfirmed[i]
+		}
+		res.errors = append(res.errors, w.errors...)
+	}
+	res.errors = res.errors[:min(len(res.errors), sampleErrors)]
+	slices.Sort(res.latencies)
+	return res
+}
+
+func (r result) total() int {
+	var n int
+	for _, count := range r.counts {
+		n += count
+	}
+	return n
+}
+
+// percentile returns the latency at or below which the fraction p of
+// requests completed, by the nearest-rank method.
+func (r result) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(r.latencies))))
+	return r.latencies[max(rank, 1)-1]
+}
+
+const (
+	// hotProducts is how many of the most demanded products the report lists.
+	hotProducts = 5
+	// sampleErrors is how many unexpected errors the report quotes.
+	sampleErrors = 5
+)
+
+func (r result) print(w io.Writer, fx fixture, opts options) {
+	total := r.total()
+	fmt.Fprintf(w, "orders        %d in %s, %.1f/s with %d workers\n",
+		total, r.elapsed.Round(time.Millisecond), float64(total)/r.elapsed.Seconds(), opts.workers)
+	for kind, name := range outcomeNames {
+		fmt.Fprintf(w, "%-13s %d (%.1f%%)\n", name, r.counts[kind], percent(r.counts[kind], total))
+	}
+	if placed := r.counts[placed]; placed > 0 {
+		fmt.Fprintf(w, "placed/s      %.1f\n", float64(placed)/r.elapsed.Seconds())
+	}
+	fmt.Fprintf(w, "latency       p50 %s  p90 %s  p99 %s  max %s\n",
+		r.percentile(0.5).Round(time.Microsecond),
+		r.percentile(0.9).Round(time.Microsecond),
+		r.percentile(0.99).Round(time.Microsecond),
+		r.percentile(1).Round(time.Microsecond))
+	for _, msg := range r.errors {
+		fmt.Fprintf(w, "  error: %s\n", msg)
+	}
+
+	fmt.Fprintf(w, "\nhottest products (units)\n")
+	fmt.Fprintf(w, "  %-10s %10s %10s %10s\n", "product", "requested", "sold", "stock")
+	for i := range min(hotProducts, len(fx.products)) {
+		fmt.Fprintf(w, "  %-10d %10d %10d %10d\n", fx.products[i], r.requested[i], r.sold[i], opts.stock)
+	}
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// checkStock compares what is left of every seeded product with what the
+// run sold of it. Units went to orders the server placed and, possibly, to
+// unconfirmed ones, so what was taken must lie between the two; anything
+// else, or a quantity below zero, means stock was lost or oversold. It
+// also asks the server to reconcile quantities with the stock ledger.
+func checkStock(ctx context.Context, c *client, fx fixture, res result, opts options, w io.Writer) (bool, error) {
+	fmt.Fprintf(w, "\nconsistency\n")
+	consistent := true
+	for i, id := range fx.products {
+		product, err := c.getProduct(ctx, id)
+		if err != nil {
+			return false, fmt.Errorf("failed to read product %d: %w", id, err)
+		}
+
+		taken := opts.stock - product.Quantity
+		low, high := res.sold[i], res.sold[i]+res.unconfirmed[i]
+		switch {
+		case product.Quantity < 0 || res.sold[i] > opts.stock:
+			consistent = false
+			fmt.Fprintf(w, "  product %d OVERSOLD: %d in stock, %d sold of %d\n", id, product.Quantity, res.sold[i], opts.stock)
+		case taken < low || taken > high:
+			consistent = false
+			fmt.Fprintf(w, "  product %d MISMATCH: %d taken from stock, %d to %d sold\n", id, taken, low, high)
+		}
+	}
+	if consistent {
+		fmt.Fprintf(w, "  no product oversold; stock of all %d products matches the orders placed\n", len(fx.products))
+	}
+
+	report, err := c.reconcileStock(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to reconcile stock: %w", err)
+	}
+	if !report.Consistent {
+		consistent = false
+		for _, d := range report.Discrepancies {
+			fmt.Fprintf(w, "  product %d LEDGER: quantity %d, ledger total %d\n", d.ProductID, d.Quantity, d.LedgerTotal)
+		}
+	} else {
+		fmt.Fprintf(w, "  stock ledger reconciles with every product quantity\n")
+	}
+	return consistent, nil
+}
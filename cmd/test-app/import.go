@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/importer"
+	"pet-project/internal/service"
+)
+
+var importExtensions = map[string]string{
+	".csv":    domain.FormatCSV,
+	".ndjson": domain.FormatNDJSON,
+	".jsonl":  domain.FormatNDJSON,
+}
+
+// errImportRejected means the report was printed and listed row errors.
+var errImportRejected = errors.New("import rejected, nothing was written")
+
+// importReport is what runImport prints. It has the keys of the API's
+// import response so scripts can read either.
+type importReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Rows    int               `json:"rows"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Errors  []importRowReport `json:"errors,omitempty"`
+}
+
+type importRowReport struct {
+	Line  int    `json:"line"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+func newImportReport(result domain.ProductImportResult) importReport {
+	report := importReport{
+		DryRun:  result.DryRun,
+		Rows:    result.Rows,
+		Created: result.Created,
+		Updated: result.Updated,
+	}
+	for _, e := range result.Errors {
+		report.Errors = append(report.Errors, importRowReport{Line: e.Line, SKU: e.SKU, Error: e.Error})
+	}
+	return report
+}
+
+// runImport handles "import products [-format csv|ndjson] [-dry-run] [-upsert] FILE",
+// reading standard input when FILE is "-". The report goes to out as JSON.
+func runImport(ctx context.Context, svc service.ProductService, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "products" {
+		return errors.New("usage: import products [-format csv|ndjson] [-dry-run] [-upsert] FILE")
+	}
+
+	flags := flag.NewFlagSet("import products", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or ndjson; defaults from the file extension")
+	var opts domain.ProductImportOptions
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "validate and count without writing")
+	flags.BoolVar(&opts.Upsert, "upsert", false, "update products whose sku exists instead of rejecting them")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("import products takes exactly one file, or - for standard input")
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = importExtensions[filepath.Ext(path)]
+	}
+	if *format == "" {
+		return fmt.Errorf("cannot tell the format of %s, pass -format", path)
+	}
+
+	input := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	rows, result, err := importer.ReadProducts(input, *format, opts)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) == 0 {
+		if result, err = svc.ImportProducts(ctx, rows, opts); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newImportReport(result)); err != nil {
+		return fmt.Errorf("failed to write import report: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return errImportRejected
+	}
+	return nil
+}
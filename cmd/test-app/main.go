@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	_ "time/tzdata"
 
 	"pet-project/internal/app"
 	"pet-project/internal/config"
@@ -32,7 +33,25 @@ func main() {
 	}
 	defer repo.Close()
 
-	application := app.New(cfg, repo, logger)
+	if err := repo.Migrate(ctx); err != nil {
+		logger.Fatal(err, "Не удалось применить миграции")
+	}
+
+	application, err := app.New(cfg, repo, logger)
+	if err != nil {
+		logger.Fatal(err, "Не удалось собрать приложение")
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		err := runImport(ctx, application.Service, os.Args[2:], os.Stdout)
+		repo.Close()
+		if err != nil {
+			logger.Error(err, "Не удалось импортировать данные")
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := application.Run(ctx); err != nil {
 		logger.Fatal(err, "Не удалось запустить приложение")
 	}
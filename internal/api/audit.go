@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/validation"
+)
+
+func (h *Handler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	filter, err := auditFilter(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := validation.ValidateAuditFilter(filter); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListAuditEntries(r.Context(), filter, r.URL.Query().Get("cursor"))
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, auditPageResponse(page))
+}
+
+func auditFilter(r *http.Request) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{Entity: r.URL.Query().Get("entity")}
+
+	var err error
+	if filter.EntityID, err = queryInt64(r, "entity_id"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = queryTime(r, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = queryTime(r, "to"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = queryLimit(r); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
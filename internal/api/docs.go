@@ -0,0 +1,47 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// The docs page and its script are embedded like the spec rather than
+// loaded from a CDN, so the page runs only code shipped with the service.
+//
+//go:embed docs.html
+var docsPage []byte
+
+//go:embed docs.js
+var docsScript []byte
+
+// docsPolicy keeps the page to its own script, its inline styles and
+// requests to this service.
+const docsPolicy = "default-src 'none'; script-src 'self'; style-src 'unsafe-inline'; connect-src 'self'"
+
+func (h *Handler) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(openAPISpec); err != nil {
+		h.logger.Error(err, "Failed to write OpenAPI spec")
+	}
+}
+
+func (h *Handler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", docsPolicy)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(docsPage); err != nil {
+		h.logger.Error(err, "Failed to write docs page")
+	}
+}
+
+func (h *Handler) DocsScript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(docsScript); err != nil {
+		h.logger.Error(err, "Failed to write docs script")
+	}
+}
@@ -0,0 +1,134 @@
+// Renders openapi.json as a page of operations and schemas. It is served
+// from the binary like the spec so the docs run no third-party code.
+"use strict";
+
+const methods = ["get", "post", "put", "patch", "delete"];
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    node.setAttribute(name, value);
+  }
+  for (const child of children) {
+    if (child !== null && child !== undefined) {
+      node.append(child);
+    }
+  }
+  return node;
+}
+
+function refName(ref) {
+  return ref.slice(ref.lastIndexOf("/") + 1);
+}
+
+// schemaType describes a schema in a line, linking referenced ones.
+function schemaType(schema) {
+  if (!schema) {
+    return "";
+  }
+  if (schema.$ref) {
+    const name = refName(schema.$ref);
+    return el("a", { href: "#schema-" + name }, name);
+  }
+  if (schema.type === "array") {
+    const span = el("span", {}, "array of ");
+    span.append(schemaType(schema.items));
+    return span;
+  }
+  let type = schema.type || "object";
+  if (schema.format) {
+    type += " (" + schema.format + ")";
+  }
+  if (schema.enum) {
+    type += ": " + schema.enum.join(", ");
+  }
+  return type;
+}
+
+function table(headings, rows) {
+  const head = el("tr", {}, ...headings.map((h) => el("th", {}, h)));
+  return el("table", {}, head, ...rows.map((cells) => el("tr", {}, ...cells.map((c) => el("td", {}, c)))));
+}
+
+function contentSchema(content) {
+  const media = Object.keys(content || {});
+  if (media.length === 0) {
+    return null;
+  }
+  const span = el("span", {}, el("code", {}, media.join(", ")), " ");
+  span.append(schemaType(content[media[0]].schema));
+  return span;
+}
+
+function operation(path, method, op) {
+  const body = el("div", {});
+  if (op.description) {
+    body.append(el("p", {}, op.description));
+  }
+  if (op.parameters && op.parameters.length > 0) {
+    body.append(el("h4", {}, "Parameters"), table(["Name", "In", "Type", "Description"], op.parameters.map((p) => [
+      el("code", {}, p.name + (p.required ? " *" : "")), p.in, schemaType(p.schema), p.description || "",
+    ])));
+  }
+  if (op.requestBody) {
+    body.append(el("h4", {}, "Request body"), el("p", {}, contentSchema(op.requestBody.content)));
+  }
+  body.append(el("h4", {}, "Responses"), table(["Status", "Description", "Body"], Object.entries(op.responses || {}).map(([status, r]) => [
+    el("code", {}, status), r.description || "", contentSchema(r.content) || "",
+  ])));
+
+  const summary = el("summary", {},
+    el("span", { class: "method " + method }, method),
+    el("span", { class: "path" }, path), " ",
+    el("span", { class: "muted" }, op.summary || ""));
+  return el("details", {}, summary, body);
+}
+
+function schema(name, s) {
+  const required = new Set(s.required || []);
+  const rows = Object.entries(s.properties || {}).map(([prop, p]) => [
+    el("code", {}, prop + (required.has(prop) ? " *" : "")), schemaType(p), p.description || "",
+  ]);
+  const body = el("div", {});
+  if (s.description) {
+    body.append(el("p", {}, s.description));
+  }
+  body.append(rows.length > 0 ? table(["Field", "Type", "Description"], rows) : el("p", {}, schemaType(s)));
+  return el("details", { id: "schema-" + name }, el("summary", {}, el("code", {}, name)), body);
+}
+
+function render(spec) {
+  const info = spec.info || {};
+  const page = [el("h1", {}, (info.title || "API") + " ", el("span", { class: "muted" }, info.version || ""))];
+  if (info.description) {
+    page.push(el("p", {}, info.description));
+  }
+  page.push(el("p", {}, el("a", { href: "/openapi.json" }, "openapi.json"), " · * marks required fields"));
+
+  page.push(el("h2", {}, "Operations"));
+  for (const [path, ops] of Object.entries(spec.paths || {})) {
+    for (const method of methods) {
+      if (ops[method]) {
+        page.push(operation(path, method, ops[method]));
+      }
+    }
+  }
+
+  page.push(el("h2", {}, "Schemas"));
+  for (const [name, s] of Object.entries((spec.components || {}).schemas || {})) {
+    page.push(schema(name, s));
+  }
+  document.getElementById("docs").replaceChildren(...page);
+}
+
+fetch("/openapi.json")
+  .then((response) => {
+    if (!response.ok) {
+      throw new Error("status " + response.status);
+    }
+    return response.json();
+  })
+  .then(render)
+  .catch((err) => {
+    document.getElementById("docs").replaceChildren(el("p", {}, "Failed to load /openapi.json: " + err.message));
+  });
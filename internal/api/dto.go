@@ -0,0 +1,698 @@
+package api
+
+import (
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+// The types below are the JSON the API reads and writes. Handlers map them
+// to and from the domain types, so a field only reaches a client when a
+// response type has it: passwords are accepted on create and never
+// returned, and webhook secrets are returned on create only.
+
+type CreateUserRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Age       int    `json:"age"`
+	IsMarried bool   `json:"is_married"`
+	Password  string `json:"password"`
+}
+
+func (req CreateUserRequest) toDomain() domain.User {
+	return domain.User{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Age:       req.Age,
+		IsMarried: req.IsMarried,
+		Password:  req.Password,
+	}
+}
+
+type UpdateUserRequest struct {
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	IsMarried *bool   `json:"is_married,omitempty"`
+}
+
+func (req UpdateUserRequest) toDomain() domain.UserUpdate {
+	return domain.UserUpdate{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		IsMarried: req.IsMarried,
+	}
+}
+
+type UserResponse struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	FullName  string `json:"full_name"`
+	Age       int    `json:"age"`
+	IsMarried bool   `json:"is_married"`
+	Version   int64  `json:"version"`
+}
+
+func userResponse(user domain.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		FullName:  user.FullName,
+		Age:       user.Age,
+		IsMarried: user.IsMarried,
+		Version:   user.Version,
+	}
+}
+
+type UserPageResponse struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func userPageResponse(page domain.UserPage) UserPageResponse {
+	users := make([]UserResponse, len(page.Users))
+	for i, user := range page.Users {
+		users[i] = userResponse(user)
+	}
+	return UserPageResponse{Users: users, NextCursor: page.NextCursor}
+}
+
+type CreateProductRequest struct {
+	SKU              string   `json:"sku,omitempty"`
+	Description      string   `json:"description"`
+	Tags             []string `json:"tags"`
+	Quantity         int      `json:"quantity"`
+	Price            float64  `json:"price"`
+	TaxClass         string   `json:"tax_class"`
+	ReorderThreshold *int     `json:"reorder_threshold,omitempty"`
+}
+
+func (req CreateProductRequest) toDomain() domain.Product {
+	return domain.Product{
+		SKU:              req.SKU,
+		Description:      req.Description,
+		Tags:             req.Tags,
+		Quantity:         req.Quantity,
+		Price:            req.Price,
+		TaxClass:         req.TaxClass,
+		ReorderThreshold: req.ReorderThreshold,
+	}
+}
+
+type UpdateProductRequest struct {
+	Description      *string   `json:"description,omitempty"`
+	Tags             *[]string `json:"tags,omitempty"`
+	Price            *float64  `json:"price,omitempty"`
+	TaxClass         *string   `json:"tax_class,omitempty"`
+	ReorderThreshold *int      `json:"reorder_threshold,omitempty"`
+}
+
+func (req UpdateProductRequest) toDomain() domain.ProductUpdate {
+	return domain.ProductUpdate{
+		Description:      req.Description,
+		Tags:             req.Tags,
+		Price:            req.Price,
+		TaxClass:         req.TaxClass,
+		ReorderThreshold: req.ReorderThreshold,
+	}
+}
+
+type ProductResponse struct {
+	ID               int64    `json:"id"`
+	SKU              string   `json:"sku,omitempty"`
+	Description      string   `json:"description"`
+	Tags             []string `json:"tags"`
+	Quantity         int      `json:"quantity"`
+	Price            float64  `json:"price"`
+	TaxClass         string   `json:"tax_class"`
+	ReorderThreshold *int     `json:"reorder_threshold,omitempty"`
+	Version          int64    `json:"version"`
+}
+
+func productResponse(product domain.Product) ProductResponse {
+	return ProductResponse{
+		ID:               product.ID,
+		SKU:              product.SKU,
+		Description:      product.Description,
+		Tags:             product.Tags,
+		Quantity:         product.Quantity,
+		Price:            product.Price,
+		TaxClass:         product.TaxClass,
+		ReorderThreshold: product.ReorderThreshold,
+		Version:          product.Version,
+	}
+}
+
+func productResponses(products []domain.Product) []ProductResponse {
+	responses := make([]ProductResponse, len(products))
+	for i, product := range products {
+		responses[i] = productResponse(product)
+	}
+	return responses
+}
+
+type ProductVersionResponse struct {
+	ProductID        int64     `json:"product_id"`
+	Version          int64     `json:"version"`
+	Description      string    `json:"description"`
+	Tags             []string  `json:"tags"`
+	Price            float64   `json:"price"`
+	TaxClass         string    `json:"tax_class"`
+	ReorderThreshold *int      `json:"reorder_threshold,omitempty"`
+	ValidFrom        time.Time `json:"valid_from"`
+}
+
+type ProductVersionListResponse struct {
+	Versions []ProductVersionResponse `json:"versions"`
+}
+
+func productVersionListResponse(list domain.ProductVersionList) ProductVersionListResponse {
+	versions := make([]ProductVersionResponse, len(list.Versions))
+	for i, v := range list.Versions {
+		versions[i] = ProductVersionResponse{
+			ProductID:        v.ProductID,
+			Version:          v.Version,
+			Description:      v.Description,
+			Tags:             v.Tags,
+			Price:            v.Price,
+			TaxClass:         v.TaxClass,
+			ReorderThreshold: v.ReorderThreshold,
+			ValidFrom:        v.ValidFrom,
+		}
+	}
+	return ProductVersionListResponse{Versions: versions}
+}
+
+type CreateOrderRequest struct {
+	UserID    int64              `json:"user_id"`
+	Items     []OrderItemRequest `json:"items"`
+	PromoCode string             `json:"promo_code,omitempty"`
+}
+
+type OrderItemRequest struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+func (req CreateOrderRequest) toDomain() domain.NewOrder {
+	order := domain.NewOrder{UserID: req.UserID, PromoCode: req.PromoCode}
+	for _, item := range req.Items {
+		order.Items = append(order.Items, domain.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	return order
+}
+
+type OrderResponse struct {
+	ID          int64               `json:"id"`
+	UserID      int64               `json:"user_id"`
+	Status      string              `json:"status"`
+	CreatedAt   time.Time           `json:"created_at"`
+	CancelledAt *time.Time          `json:"cancelled_at,omitempty"`
+	Subtotal    float64             `json:"subtotal"`
+	Discount    float64             `json:"discount"`
+	PromoCode   string              `json:"promo_code,omitempty"`
+	NetTotal    float64             `json:"net_total"`
+	TaxTotal    float64             `json:"tax_total"`
+	TotalPrice  float64             `json:"total_price"`
+	Lines       []OrderLineResponse `json:"order_products"`
+}
+
+type OrderLineResponse struct {
+	OrderID        int64    `json:"order_id"`
+	ProductID      int64    `json:"product_id"`
+	ProductVersion int64    `json:"product_version"`
+	Description    string   `json:"description"`
+	Tags           []string `json:"tags"`
+	Quantity       int      `json:"quantity"`
+	Price          float64  `json:"price"`
+	Discount       float64  `json:"discount"`
+	TaxClass       string   `json:"tax_class,omitempty"`
+	TaxRate        float64  `json:"tax_rate"`
+	TaxAmount      float64  `json:"tax_amount"`
+}
+
+func orderResponse(order domain.Order) OrderResponse {
+	response := OrderResponse{
+		ID:          order.ID,
+		UserID:      order.UserID,
+		Status:      order.Status,
+		CreatedAt:   order.CreatedAt,
+		CancelledAt: order.CancelledAt,
+		Subtotal:    order.Subtotal,
+		Discount:    order.Discount,
+		PromoCode:   order.PromoCode,
+		NetTotal:    order.NetTotal,
+		TaxTotal:    order.TaxTotal,
+		TotalPrice:  order.TotalPrice,
+	}
+	for _, line := range order.OrderProduct {
+		response.Lines = append(response.Lines, OrderLineResponse{
+			OrderID:        line.OrderID,
+			ProductID:      line.ProductID,
+			ProductVersion: line.ProductVersion,
+			Description:    line.Description,
+			Tags:           line.Tags,
+			Quantity:       line.Quantity,
+			Price:          line.Price,
+			Discount:       line.Discount,
+			TaxClass:       line.TaxClass,
+			TaxRate:        line.TaxRate,
+			TaxAmount:      line.TaxAmount,
+		})
+	}
+	return response
+}
+
+type StockAdjustmentRequest struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+	Kind   string `json:"kind,omitempty"`
+}
+
+func (req StockAdjustmentRequest) toDomain() domain.StockAdjustment {
+	return domain.StockAdjustment{Delta: req.Delta, Reason: req.Reason, Kind: req.Kind}
+}
+
+type StockMovementResponse struct {
+	ID            int64     `json:"id"`
+	ProductID     int64     `json:"product_id"`
+	Kind          string    `json:"kind"`
+	Delta         int       `json:"delta"`
+	QuantityAfter int       `json:"quantity_after"`
+	Reason        string    `json:"reason"`
+	OrderID       *int64    `json:"order_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type StockMovementPageResponse struct {
+	Movements  []StockMovementResponse `json:"movements"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+func stockMovementPageResponse(page domain.StockMovementPage) StockMovementPageResponse {
+	movements := make([]StockMovementResponse, len(page.Movements))
+	for i, m := range page.Movements {
+		movements[i] = StockMovementResponse{
+			ID:            m.ID,
+			ProductID:     m.ProductID,
+			Kind:          m.Kind,
+			Delta:         m.Delta,
+			QuantityAfter: m.QuantityAfter,
+			Reason:        m.Reason,
+			OrderID:       m.OrderID,
+			CreatedAt:     m.CreatedAt,
+		}
+	}
+	return StockMovementPageResponse{Movements: movements, NextCursor: page.NextCursor}
+}
+
+type StockDiscrepancyResponse struct {
+	ProductID   int64 `json:"product_id"`
+	Quantity    int   `json:"quantity"`
+	LedgerTotal int   `json:"ledger_total"`
+}
+
+type StockReconciliationResponse struct {
+	Consistent    bool                       `json:"consistent"`
+	Discrepancies []StockDiscrepancyResponse `json:"discrepancies"`
+}
+
+func stockReconciliationResponse(report domain.StockReconciliation) StockReconciliationResponse {
+	discrepancies := make([]StockDiscrepancyResponse, len(report.Discrepancies))
+	for i, d := range report.Discrepancies {
+		discrepancies[i] = StockDiscrepancyResponse{ProductID: d.ProductID, Quantity: d.Quantity, LedgerTotal: d.LedgerTotal}
+	}
+	return StockReconciliationResponse{Consistent: report.Consistent, Discrepancies: discrepancies}
+}
+
+type ImportRowErrorResponse struct {
+	Line  int    `json:"line"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+type ProductImportResponse struct {
+	DryRun  bool                     `json:"dry_run"`
+	Rows    int                      `json:"rows"`
+	Created int                      `json:"created"`
+	Updated int                      `json:"updated"`
+	Errors  []ImportRowErrorResponse `json:"errors,omitempty"`
+}
+
+func productImportResponse(result domain.ProductImportResult) ProductImportResponse {
+	response := ProductImportResponse{
+		DryRun:  result.DryRun,
+		Rows:    result.Rows,
+		Created: result.Created,
+		Updated: result.Updated,
+	}
+	for _, e := range result.Errors {
+		response.Errors = append(response.Errors, ImportRowErrorResponse{Line: e.Line, SKU: e.SKU, Error: e.Error})
+	}
+	return response
+}
+
+type OrderExportRowResponse struct {
+	OrderID        int64     `json:"order_id"`
+	UserID         int64     `json:"user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Status         string    `json:"status"`
+	ProductID      int64     `json:"product_id"`
+	ProductVersion int64     `json:"product_version"`
+	Description    string    `json:"description"`
+	Quantity       int       `json:"quantity"`
+	Price          float64   `json:"price"`
+	Discount       float64   `json:"discount"`
+	TaxAmount      float64   `json:"tax_amount"`
+	OrderTotal     float64   `json:"order_total"`
+}
+
+func orderExportRowResponse(row domain.OrderExportRow) OrderExportRowResponse {
+	return OrderExportRowResponse{
+		OrderID:        row.OrderID,
+		UserID:         row.UserID,
+		CreatedAt:      row.CreatedAt,
+		Status:         row.Status,
+		ProductID:      row.ProductID,
+		ProductVersion: row.ProductVersion,
+		Description:    row.Description,
+		Quantity:       row.Quantity,
+		Price:          row.Price,
+		Discount:       row.Discount,
+		TaxAmount:      row.TaxAmount,
+		OrderTotal:     row.OrderTotal,
+	}
+}
+
+type RevenueRowResponse struct {
+	PeriodStart string  `json:"period_start"`
+	Orders      int     `json:"orders"`
+	Items       int     `json:"items"`
+	GrossSales  float64 `json:"gross_sales"`
+	Discount    float64 `json:"discount"`
+	Revenue     float64 `json:"revenue"`
+	Tax         float64 `json:"tax"`
+}
+
+type RevenueReportResponse struct {
+	From     time.Time            `json:"from"`
+	To       time.Time            `json:"to"`
+	Period   string               `json:"period"`
+	TimeZone string               `json:"time_zone"`
+	Rows     []RevenueRowResponse `json:"rows"`
+}
+
+func revenueReportResponse(report domain.RevenueReport) RevenueReportResponse {
+	rows := make([]RevenueRowResponse, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = RevenueRowResponse{
+			PeriodStart: row.PeriodStart,
+			Orders:      row.Orders,
+			Items:       row.Items,
+			GrossSales:  row.GrossSales,
+			Discount:    row.Discount,
+			Revenue:     row.Revenue,
+			Tax:         row.Tax,
+		}
+	}
+	return RevenueReportResponse{From: report.From, To: report.To, Period: report.Period, TimeZone: report.TimeZone, Rows: rows}
+}
+
+type ProductSalesResponse struct {
+	ProductID   int64   `json:"product_id"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	Orders      int     `json:"orders"`
+	Revenue     float64 `json:"revenue"`
+}
+
+type TopProductsReportResponse struct {
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"`
+	Products []ProductSalesResponse `json:"products"`
+}
+
+func topProductsReportResponse(report domain.TopProductsReport) TopProductsReportResponse {
+	products := make([]ProductSalesResponse, len(report.Products))
+	for i, p := range report.Products {
+		products[i] = ProductSalesResponse{
+			ProductID:   p.ProductID,
+			Description: p.Description,
+			Quantity:    p.Quantity,
+			Orders:      p.Orders,
+			Revenue:     p.Revenue,
+		}
+	}
+	return TopProductsReportResponse{From: report.From, To: report.To, Products: products}
+}
+
+type CustomerSalesResponse struct {
+	UserID    int64   `json:"user_id"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Orders    int     `json:"orders"`
+	Items     int     `json:"items"`
+	Revenue   float64 `json:"revenue"`
+}
+
+type TopCustomersReportResponse struct {
+	From      time.Time               `json:"from"`
+	To        time.Time               `json:"to"`
+	Customers []CustomerSalesResponse `json:"customers"`
+}
+
+func topCustomersReportResponse(report domain.TopCustomersReport) TopCustomersReportResponse {
+	customers := make([]CustomerSalesResponse, len(report.Customers))
+	for i, c := range report.Customers {
+		customers[i] = CustomerSalesResponse{
+			UserID:    c.UserID,
+			FirstName: c.FirstName,
+			LastName:  c.LastName,
+			Orders:    c.Orders,
+			Items:     c.Items,
+			Revenue:   c.Revenue,
+		}
+	}
+	return TopCustomersReportResponse{From: report.From, To: report.To, Customers: customers}
+}
+
+type CreatePromoCodeRequest struct {
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Value          float64    `json:"value"`
+	MinOrderValue  float64    `json:"min_order_value"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxUses        *int       `json:"max_uses,omitempty"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user,omitempty"`
+	ProductIDs     []int64    `json:"product_ids"`
+	Tags           []string   `json:"tags"`
+}
+
+func (req CreatePromoCodeRequest) toDomain() domain.PromoCode {
+	return domain.PromoCode{
+		Code:           req.Code,
+		Kind:           req.Kind,
+		Value:          req.Value,
+		MinOrderValue:  req.MinOrderValue,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		ProductIDs:     req.ProductIDs,
+		Tags:           req.Tags,
+	}
+}
+
+type PromoCodeResponse struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Value          float64    `json:"value"`
+	MinOrderValue  float64    `json:"min_order_value"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxUses        *int       `json:"max_uses,omitempty"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user,omitempty"`
+	ProductIDs     []int64    `json:"product_ids"`
+	Tags           []string   `json:"tags"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func promoCodeResponse(promo domain.PromoCode) PromoCodeResponse {
+	return PromoCodeResponse{
+		ID:             promo.ID,
+		Code:           promo.Code,
+		Kind:           promo.Kind,
+		Value:          promo.Value,
+		MinOrderValue:  promo.MinOrderValue,
+		ValidFrom:      promo.ValidFrom,
+		ValidUntil:     promo.ValidUntil,
+		MaxUses:        promo.MaxUses,
+		MaxUsesPerUser: promo.MaxUsesPerUser,
+		ProductIDs:     promo.ProductIDs,
+		Tags:           promo.Tags,
+		CreatedAt:      promo.CreatedAt,
+	}
+}
+
+type PromoCodeListResponse struct {
+	PromoCodes []PromoCodeResponse `json:"promo_codes"`
+}
+
+func promoCodeListResponse(promos []domain.PromoCode) PromoCodeListResponse {
+	responses := make([]PromoCodeResponse, len(promos))
+	for i, promo := range promos {
+		responses[i] = promoCodeResponse(promo)
+	}
+	return PromoCodeListResponse{PromoCodes: responses}
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+func (req CreateWebhookRequest) toDomain() domain.WebhookSubscription {
+	return domain.WebhookSubscription{URL: req.URL, EventTypes: req.EventTypes, Secret: req.Secret}
+}
+
+// CreateWebhookResponse is WebhookResponse with the secret, which the
+// subscriber sees only once.
+type CreateWebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func createWebhookResponse(sub domain.WebhookSubscription) CreateWebhookResponse {
+	return CreateWebhookResponse{
+		ID:         sub.ID,
+		URL:        sub.URL,
+		EventTypes: sub.EventTypes,
+		Secret:     sub.Secret,
+		CreatedAt:  sub.CreatedAt,
+	}
+}
+
+type WebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func webhookResponse(sub domain.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{ID: sub.ID, URL: sub.URL, EventTypes: sub.EventTypes, CreatedAt: sub.CreatedAt}
+}
+
+type WebhookListResponse struct {
+	Subscriptions []WebhookResponse `json:"subscriptions"`
+}
+
+func webhookListResponse(subs []domain.WebhookSubscription) WebhookListResponse {
+	responses := make([]WebhookResponse, len(subs))
+	for i, sub := range subs {
+		responses[i] = webhookResponse(sub)
+	}
+	return WebhookListResponse{Subscriptions: responses}
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64                    `json:"id"`
+	SubscriptionID int64                    `json:"subscription_id"`
+	EventID        int64                    `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	ResponseCode   *int                     `json:"response_code,omitempty"`
+	LastError      *string                  `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time                `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time               `json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	Log            []WebhookAttemptResponse `json:"log,omitempty"`
+}
+
+type WebhookAttemptResponse struct {
+	AttemptedAt  time.Time `json:"attempted_at"`
+	ResponseCode *int      `json:"response_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMS   int       `json:"duration_ms"`
+}
+
+func webhookDeliveryResponse(delivery domain.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseCode:   delivery.ResponseCode,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	for _, attempt := range delivery.Log {
+		response.Log = append(response.Log, WebhookAttemptResponse{
+			AttemptedAt:  attempt.AttemptedAt,
+			ResponseCode: attempt.ResponseCode,
+			Error:        attempt.Error,
+			DurationMS:   attempt.DurationMS,
+		})
+	}
+	return response
+}
+
+type WebhookDeliveryPageResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+}
+
+func webhookDeliveryPageResponse(page domain.WebhookDeliveryPage) WebhookDeliveryPageResponse {
+	deliveries := make([]WebhookDeliveryResponse, len(page.Deliveries))
+	for i, delivery := range page.Deliveries {
+		deliveries[i] = webhookDeliveryResponse(delivery)
+	}
+	return WebhookDeliveryPageResponse{Deliveries: deliveries, NextCursor: page.NextCursor}
+}
+
+type AuditEntryResponse struct {
+	ID        int64          `json:"id"`
+	Actor     string         `json:"actor"`
+	RequestID string         `json:"request_id,omitempty"`
+	Entity    string         `json:"entity"`
+	EntityID  int64          `json:"entity_id"`
+	Action    string         `json:"action"`
+	Before    map[string]any `json:"before,omitempty"`
+	After     map[string]any `json:"after,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type AuditPageResponse struct {
+	Entries    []AuditEntryResponse `json:"entries"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+func auditPageResponse(page domain.AuditPage) AuditPageResponse {
+	entries := make([]AuditEntryResponse, len(page.Entries))
+	for i, e := range page.Entries {
+		entries[i] = AuditEntryResponse{
+			ID:        e.ID,
+			Actor:     e.Actor,
+			RequestID: e.RequestID,
+			Entity:    e.Entity,
+			EntityID:  e.EntityID,
+			Action:    e.Action,
+			Before:    e.Before,
+			After:     e.After,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+	return AuditPageResponse{Entries: entries, NextCursor: page.NextCursor}
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+func TestUserResponseOmitsPassword(t *testing.T) {
+	user := domain.User{ID: 1, FirstName: "Ivan", LastName: "Petrov", FullName: "Ivan Petrov", Age: 30, IsMarried: true, Password: "password123", Version: 2}
+
+	data, err := json.Marshal(userPageResponse(domain.UserPage{Users: []domain.User{user}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "password") {
+		t.Errorf("response leaks the password: %s", data)
+	}
+
+	want := UserResponse{ID: 1, FirstName: "Ivan", LastName: "Petrov", FullName: "Ivan Petrov", Age: 30, IsMarried: true, Version: 2}
+	if got := userResponse(user); got != want {
+		t.Errorf("userResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreateUserRequestToDomain(t *testing.T) {
+	var req CreateUserRequest
+	body := `{"first_name":"Ivan","last_name":"Petrov","age":30,"is_married":true,"password":"password123","id":9,"version":4}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	want := domain.User{FirstName: "Ivan", LastName: "Petrov", Age: 30, IsMarried: true, Password: "password123"}
+	if got := req.toDomain(); got != want {
+		t.Errorf("toDomain = %+v, want %+v", got, want)
+	}
+}
+
+func TestProductMapping(t *testing.T) {
+	req := CreateProductRequest{SKU: "TEA-1", Description: "Tea", Tags: []string{"drinks"}, Quantity: 10, Price: 4.5, TaxClass: "reduced", ReorderThreshold: ptr(3)}
+	product := req.toDomain()
+	product.ID, product.Version = 7, 1
+
+	want := ProductResponse{ID: 7, SKU: "TEA-1", Description: "Tea", Tags: []string{"drinks"}, Quantity: 10, Price: 4.5, TaxClass: "reduced", ReorderThreshold: ptr(3), Version: 1}
+	if got := productResponse(product); !reflect.DeepEqual(got, want) {
+		t.Errorf("productResponse = %+v, want %+v", got, want)
+	}
+
+	update := UpdateProductRequest{Description: ptr("Green tea"), Tags: &[]string{}, Price: ptr(5.0), TaxClass: ptr("standard"), ReorderThreshold: ptr(4)}
+	wantUpdate := domain.ProductUpdate{Description: update.Description, Tags: update.Tags, Price: update.Price, TaxClass: update.TaxClass, ReorderThreshold: update.ReorderThreshold}
+	if got := update.toDomain(); got != wantUpdate {
+		t.Errorf("toDomain = %+v, want %+v", got, wantUpdate)
+	}
+}
+
+func TestOrderMapping(t *testing.T) {
+	req := CreateOrderRequest{UserID: 1, Items: []OrderItemRequest{{ProductID: 2, Quantity: 3}, {ProductID: 4, Quantity: 1}}, PromoCode: "SPRING10"}
+	wantOrder := domain.NewOrder{UserID: 1, Items: []domain.OrderItem{{ProductID: 2, Quantity: 3}, {ProductID: 4, Quantity: 1}}, PromoCode: "SPRING10"}
+	if got := req.toDomain(); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("toDomain = %+v, want %+v", got, wantOrder)
+	}
+
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	order := domain.Order{
+		ID: 5, UserID: 1, Status: domain.OrderStatusCreated, CreatedAt: createdAt,
+		Subtotal: 10, Discount: 1, PromoCode: "SPRING10", NetTotal: 9, TaxTotal: 1.8, TotalPrice: 10.8,
+		OrderProduct: []domain.OrderProduct{{
+			OrderID: 5, ProductID: 2, ProductVersion: 3, Description: "Tea", Tags: []string{"drinks"},
+			Quantity: 2, Price: 5, Discount: 1, TaxClass: "standard", TaxRate: 0.2, TaxAmount: 1.8,
+		}},
+	}
+	want := OrderResponse{
+		ID: 5, UserID: 1, Status: domain.OrderStatusCreated, CreatedAt: createdAt,
+		Subtotal: 10, Discount: 1, PromoCode: "SPRING10", NetTotal: 9, TaxTotal: 1.8, TotalPrice: 10.8,
+		Lines: []OrderLineResponse{{
+			OrderID: 5, ProductID: 2, ProductVersion: 3, Description: "Tea", Tags: []string{"drinks"},
+			Quantity: 2, Price: 5, Discount: 1, TaxClass: "standard", TaxRate: 0.2, TaxAmount: 1.8,
+		}},
+	}
+	if got := orderResponse(order); !reflect.DeepEqual(got, want) {
+		t.Errorf("orderResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestWebhookResponsesShowSecretOnCreateOnly(t *testing.T) {
+	req := CreateWebhookRequest{URL: "https://partner.example/hooks", EventTypes: []string{domain.EventOrderCreated}, Secret: "0123456789abcdef"}
+	sub := req.toDomain()
+	want := domain.WebhookSubscription{URL: req.URL, EventTypes: req.EventTypes, Secret: req.Secret}
+	if !reflect.DeepEqual(sub, want) {
+		t.Errorf("toDomain = %+v, want %+v", sub, want)
+	}
+	sub.ID, sub.CreatedAt = 1, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	created := createWebhookResponse(sub)
+	if created.Secret != sub.Secret {
+		t.Errorf("createWebhookResponse secret = %q, want %q", created.Secret, sub.Secret)
+	}
+
+	for name, response := range map[string]any{
+		"webhookResponse":     webhookResponse(sub),
+		"webhookListResponse": webhookListResponse([]domain.WebhookSubscription{sub}),
+	} {
+		data, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") || strings.Contains(string(data), sub.Secret) {
+			t.Errorf("%s leaks the secret: %s", name, data)
+		}
+	}
+}
+
+func TestWebhookDeliveryMapping(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	delivery := domain.WebhookDelivery{
+		ID: 2, SubscriptionID: 1, EventID: 9, EventType: domain.EventOrderCreated, Status: domain.DeliveryFailed,
+		Attempts: 1, ResponseCode: ptr(503), LastError: ptr("subscriber responded with status 503"),
+		NextAttemptAt: now.Add(time.Minute), LastAttemptAt: &now, CreatedAt: now,
+		Log:     []domain.WebhookAttempt{{AttemptedAt: now, ResponseCode: ptr(503), Error: "subscriber responded with status 503", DurationMS: 12}},
+		Request: &domain.WebhookRequest{URL: "https://partner.example/hooks", Secret: "0123456789abcdef"},
+	}
+	want := WebhookDeliveryResponse{
+		ID: 2, SubscriptionID: 1, EventID: 9, EventType: domain.EventOrderCreated, Status: domain.DeliveryFailed,
+		Attempts: 1, ResponseCode: ptr(503), LastError: ptr("subscriber responded with status 503"),
+		NextAttemptAt: now.Add(time.Minute), LastAttemptAt: &now, CreatedAt: now,
+		Log: []WebhookAttemptResponse{{AttemptedAt: now, ResponseCode: ptr(503), Error: "subscriber responded with status 503", DurationMS: 12}},
+	}
+	page := webhookDeliveryPageResponse(domain.WebhookDeliveryPage{Deliveries: []domain.WebhookDelivery{delivery}, NextCursor: "2"})
+	if len(page.Deliveries) != 1 || !reflect.DeepEqual(page.Deliveries[0], want) || page.NextCursor != "2" {
+		t.Errorf("webhookDeliveryPageResponse = %+v, want one delivery %+v", page, want)
+	}
+}
+
+func TestPromoCodeMapping(t *testing.T) {
+	until := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	req := CreatePromoCodeRequest{
+		Code: "SPRING10", Kind: domain.PromoPercent, Value: 10, MinOrderValue: 20, ValidUntil: &until,
+		MaxUses: ptr(100), MaxUsesPerUser: ptr(1), ProductIDs: []int64{1}, Tags: []string{"drinks"},
+	}
+	promo := req.toDomain()
+	promo.ID, promo.CreatedAt = 3, until.Add(-24*time.Hour)
+
+	want := PromoCodeResponse{
+		ID: 3, Code: "SPRING10", Kind: domain.PromoPercent, Value: 10, MinOrderValue: 20, ValidUntil: &until,
+		MaxUses: ptr(100), MaxUsesPerUser: ptr(1), ProductIDs: []int64{1}, Tags: []string{"drinks"}, CreatedAt: promo.CreatedAt,
+	}
+	list := promoCodeListResponse([]domain.PromoCode{promo})
+	if len(list.PromoCodes) != 1 || !reflect.DeepEqual(list.PromoCodes[0], want) {
+		t.Errorf("promoCodeListResponse = %+v, want one promo code %+v", list, want)
+	}
+}
+
+func TestStockMapping(t *testing.T) {
+	req := StockAdjustmentRequest{Delta: -2, Reason: "damaged", Kind: domain.MovementCorrection}
+	if got, want := req.toDomain(), (domain.StockAdjustment{Delta: -2, Reason: "damaged", Kind: domain.MovementCorrection}); got != want {
+		t.Errorf("toDomain = %+v, want %+v", got, want)
+	}
+
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	page := stockMovementPageResponse(domain.StockMovementPage{
+		Movements:  []domain.StockMovement{{ID: 4, ProductID: 1, Kind: domain.MovementOrder, Delta: -2, QuantityAfter: 8, Reason: "order 5", OrderID: ptr(int64(5)), CreatedAt: createdAt}},
+		NextCursor: "4",
+	})
+	wantPage := StockMovementPageResponse{
+		Movements:  []StockMovementResponse{{ID: 4, ProductID: 1, Kind: domain.MovementOrder, Delta: -2, QuantityAfter: 8, Reason: "order 5", OrderID: ptr(int64(5)), CreatedAt: createdAt}},
+		NextCursor: "4",
+	}
+	if !reflect.DeepEqual(page, wantPage) {
+		t.Errorf("stockMovementPageResponse = %+v, want %+v", page, wantPage)
+	}
+
+	data, err := json.Marshal(stockReconciliationResponse(domain.StockReconciliation{Consistent: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"consistent":true,"discrepancies":[]}`; string(data) != want {
+		t.Errorf("stockReconciliationResponse encodes as %s, want %s", data, want)
+	}
+}
+
+func TestProductImportResponse(t *testing.T) {
+	result := domain.ProductImportResult{DryRun: true, Rows: 3, Errors: []domain.ImportRowError{{Line: 2, SKU: "TEA-1", Error: "price is required"}}}
+	want := ProductImportResponse{DryRun: true, Rows: 3, Errors: []ImportRowErrorResponse{{Line: 2, SKU: "TEA-1", Error: "price is required"}}}
+	if got := productImportResponse(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("productImportResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestReportMapping(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	revenue := revenueReportResponse(domain.RevenueReport{
+		From: from, To: to, Period: domain.ReportPeriodDay, TimeZone: "UTC",
+		Rows: []domain.RevenueRow{{PeriodStart: "2026-03-01", Orders: 2, Items: 3, GrossSales: 30, Discount: 3, Revenue: 27, Tax: 4.5}},
+	})
+	wantRevenue := RevenueReportResponse{
+		From: from, To: to, Period: domain.ReportPeriodDay, TimeZone: "UTC",
+		Rows: []RevenueRowResponse{{PeriodStart: "2026-03-01", Orders: 2, Items: 3, GrossSales: 30, Discount: 3, Revenue: 27, Tax: 4.5}},
+	}
+	if !reflect.DeepEqual(revenue, wantRevenue) {
+		t.Errorf("revenueReportResponse = %+v, want %+v", revenue, wantRevenue)
+	}
+
+	products := topProductsReportResponse(domain.TopProductsReport{
+		From: from, To: to, Products: []domain.ProductSales{{ProductID: 1, Description: "Tea", Quantity: 3, Orders: 2, Revenue: 13.5}},
+	})
+	wantProducts := TopProductsReportResponse{
+		From: from, To: to, Products: []ProductSalesResponse{{ProductID: 1, Description: "Tea", Quantity: 3, Orders: 2, Revenue: 13.5}},
+	}
+	if !reflect.DeepEqual(products, wantProducts) {
+		t.Errorf("topProductsReportResponse = %+v, want %+v", products, wantProducts)
+	}
+
+	customers := topCustomersReportResponse(domain.TopCustomersReport{
+		From: from, To: to, Customers: []domain.CustomerSales{{UserID: 1, FirstName: "Ivan", LastName: "Petrov", Orders: 2, Items: 3, Revenue: 13.5}},
+	})
+	wantCustomers := TopCustomersReportResponse{
+		From: from, To: to, Customers: []CustomerSalesResponse{{UserID: 1, FirstName: "Ivan", LastName: "Petrov", Orders: 2, Items: 3, Revenue: 13.5}},
+	}
+	if !reflect.DeepEqual(customers, wantCustomers) {
+		t.Errorf("topCustomersReportResponse = %+v, want %+v", customers, wantCustomers)
+	}
+}
+
+func TestOrderExportRowResponse(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := domain.OrderExportRow{
+		OrderID: 5, UserID: 1, CreatedAt: createdAt, Status: domain.OrderStatusCreated, ProductID: 2, ProductVersion: 3,
+		Description: "Tea", Quantity: 2, Price: 5, Discount: 1, TaxAmount: 1.8, OrderTotal: 10.8,
+	}
+	want := OrderExportRowResponse{
+		OrderID: 5, UserID: 1, CreatedAt: createdAt, Status: domain.OrderStatusCreated, ProductID: 2, ProductVersion: 3,
+		Description: "Tea", Quantity: 2, Price: 5, Discount: 1, TaxAmount: 1.8, OrderTotal: 10.8,
+	}
+	if got := orderExportRowResponse(row); got != want {
+		t.Errorf("orderExportRowResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestAuditPageResponse(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	page := auditPageResponse(domain.AuditPage{
+		Entries: []domain.AuditEntry{{
+			ID: 3, Actor: "ip:192.0.2.1", RequestID: "4bf92f3577b34da6", Entity: domain.AggregateProduct, EntityID: 1,
+			Action: domain.AuditUpdate, Before: map[string]any{"price": 4.5}, After: map[string]any{"price": 5.0}, CreatedAt: createdAt,
+		}},
+		NextCursor: "3",
+	})
+	want := AuditPageResponse{
+		Entries: []AuditEntryResponse{{
+			ID: 3, Actor: "ip:192.0.2.1", RequestID: "4bf92f3577b34da6", Entity: domain.AggregateProduct, EntityID: 1,
+			Action: domain.AuditUpdate, Before: map[string]any{"price": 4.5}, After: map[string]any{"price": 5.0}, CreatedAt: createdAt,
+		}},
+		NextCursor: "3",
+	}
+	if !reflect.DeepEqual(page, want) {
+		t.Errorf("auditPageResponse = %+v, want %+v", page, want)
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pet-project/internal/service"
+)
+
+var errIfMatchRequired = errors.New("If-Match header with the resource ETag is required")
+
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatchVersion reads the row version a client last saw from If-Match.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, errIfMatchRequired
+	}
+
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		tag = header
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errors.Join(service.ErrValidation, fmt.Errorf("invalid If-Match header: %q", header))
+	}
+	return version, nil
+}
+
+func (h *Handler) preconditionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errIfMatchRequired) {
+		h.writeError(w, http.StatusPreconditionRequired, err.Error())
+		return
+	}
+	h.writeError(w, http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/validation"
+)
+
+// exportFlushEvery is how many rows are written between flushes, each of
+// which also pushes the write deadline back so a long export is not cut
+// off by the server's write timeout.
+const exportFlushEvery = 1000
+
+var orderExportColumns = []string{
+	"order_id", "user_id", "created_at", "status",
+	"product_id", "product_version", "description", "quantity", "price", "discount", "tax_amount",
+	"order_total",
+}
+
+type orderExportWriter interface {
+	Write(row domain.OrderExportRow) error
+	Flush() error
+}
+
+type csvOrderExport struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (e *csvOrderExport) Write(row domain.OrderExportRow) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.w.Write([]string{
+		strconv.FormatInt(row.OrderID, 10),
+		strconv.FormatInt(row.UserID, 10),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		row.Status,
+		strconv.FormatInt(row.ProductID, 10),
+		strconv.FormatInt(row.ProductVersion, 10),
+		row.Description,
+		strconv.Itoa(row.Quantity),
+		money(row.Price),
+		money(row.Discount),
+		money(row.TaxAmount),
+		money(row.OrderTotal),
+	})
+}
+
+// Flush also writes the header, so an empty export is still a valid file.
+func (e *csvOrderExport) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvOrderExport) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write(orderExportColumns)
+}
+
+type ndjsonOrderExport struct {
+	enc *json.Encoder
+}
+
+func (e ndjsonOrderExport) Write(row domain.OrderExportRow) error {
+	return e.enc.Encode(orderExportRowResponse(row))
+}
+
+func (e ndjsonOrderExport) Flush() error {
+	return nil
+}
+
+// attachment names a download after what it holds and the range it covers.
+func attachment(name string, from, to time.Time, ext string) string {
+	const stamp = "20060102T150405Z"
+	return fmt.Sprintf(`attachment; filename="%s-%s-%s.%s"`, name, from.UTC().Format(stamp), to.UTC().Format(stamp), ext)
+}
+
+func money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// ExportOrders streams the lines of orders created in [from, to) as CSV or
+// NDJSON. Once the first row is out the status cannot change, so a failure
+// after that aborts the connection and the client sees a truncated body
+// rather than a complete-looking file.
+func (h *Handler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	from, err := queryTime(r, "from")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := queryTime(r, "to")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validation.ValidateTimeRange(from, to); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var (
+		export      orderExportWriter
+		contentType string
+	)
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", domain.FormatCSV:
+		format = domain.FormatCSV
+		export = &csvOrderExport{w: csv.NewWriter(w)}
+		contentType = "text/csv"
+	case domain.FormatNDJSON:
+		export = ndjsonOrderExport{enc: json.NewEncoder(w)}
+		contentType = "application/x-ndjson"
+	default:
+		h.writeError(w, http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	started := false
+	start := func() {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", attachment("orders", *from, *to, format))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	written := 0
+	err = h.service.ExportOrders(r.Context(), *from, *to, func(row domain.OrderExportRow) error {
+		if !started {
+			start()
+		}
+		if err := export.Write(row); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := export.Flush(); err != nil {
+				return err
+			}
+			rc.SetWriteDeadline(time.Now().Add(h.config.HTTPServer.Timeout))
+			return rc.Flush()
+		}
+		return nil
+	})
+	if err != nil && !started {
+		h.ServiceError(w, err)
+		return
+	}
+	if err != nil {
+		h.logger.Error(err, "Order export failed midway", "rows", written)
+		panic(http.ErrAbortHandler)
+	}
+
+	if !started {
+		start()
+	}
+	if err := export.Flush(); err != nil {
+		h.logger.Error(err, "Failed to finish order export", "rows", written)
+	}
+}
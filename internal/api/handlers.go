@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 
 	"pet-project/internal/domain"
 	"pet-project/internal/service"
@@ -11,17 +12,13 @@ import (
 )
 
 func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
-
-	var user domain.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+	var req CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
+	user := req.toDomain()
 	if err := validation.ValidateCreateUser(user); err != nil {
 		h.writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -38,24 +35,121 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	id, err := validation.ValidateUserID(r.URL.Path)
+	user, err := h.service.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	h.writeJSON(w, http.StatusOK, userResponse(user))
+}
+
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := userFilter(r)
 	if err != nil {
 		h.writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	user, err := h.service.GetUserByID(r.Context(), id)
+	if err := validation.ValidateUserFilter(filter); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListUsers(r.Context(), filter, r.URL.Query().Get("cursor"))
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, userPageResponse(page))
+}
+
+func userFilter(r *http.Request) (domain.UserFilter, error) {
+	query := r.URL.Query()
+	filter := domain.UserFilter{
+		Search: query.Get("q"),
+		SortBy: "id",
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		filter.SortBy, filter.SortDesc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	}
+
+	var err error
+	if filter.MinAge, err = queryInt(r, "min_age"); err != nil {
+		return domain.UserFilter{}, err
+	}
+	if filter.MaxAge, err = queryInt(r, "max_age"); err != nil {
+		return domain.UserFilter{}, err
+	}
+	if filter.IsMarried, err = queryBool(r, "is_married"); err != nil {
+		return domain.UserFilter{}, err
+	}
+
+	if filter.Limit, err = queryLimit(r); err != nil {
+		return domain.UserFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		h.preconditionError(w, err)
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	update := req.toDomain()
+
+	if err := validation.ValidateUpdateUser(update); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.service.UpdateUser(r.Context(), id, version, update)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	h.writeJSON(w, http.StatusOK, userResponse(user))
+}
+
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
 	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.DeleteUser(r.Context(), id); err != nil {
 		h.ServiceError(w, err)
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, user)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *Handler) ServiceError(w http.ResponseWriter, err error) {
@@ -66,6 +160,8 @@ func (h *Handler) ServiceError(w http.ResponseWriter, err error) {
 		h.writeError(w, http.StatusConflict, err.Error())
 	case errors.Is(err, service.ErrNotFound):
 		h.writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPreconditionFailed):
+		h.writeError(w, http.StatusPreconditionFailed, err.Error())
 	default:
 		h.writeError(w, http.StatusInternalServerError, "internal server error")
 	}
@@ -81,9 +177,25 @@ func (h *Handler) writeJSON(w http.ResponseWriter, status int, data interface{})
 
 func (h *Handler) writeError(w http.ResponseWriter, status int, message string) {
 	h.logger.Error(nil, message)
-	h.writeJSON(w, status, map[string]string{"error": message})
+	h.writeJSON(w, status, ErrorResponse{Error: message})
 }
 
 type CreateUserResponse struct {
 	ID int64 `json:"id"`
 }
+
+type CreateProductResponse struct {
+	ID int64 `json:"id"`
+}
+
+type LowStockResponse struct {
+	Products []ProductResponse `json:"products"`
+}
+
+type CreateOrderResponse struct {
+	ID int64 `json:"id"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -1,16 +1,123 @@
 package api
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"pet-project/internal/ratelimit"
+	"pet-project/internal/reqmeta"
 )
 
+const maxRequestIDLength = 128
+
+// requestMetaMiddleware tags the request with an ID, reusing the caller's
+// X-Request-ID when it sends a sane one, and with the actor recorded in
+// the audit log. The API has no authentication yet, so the actor is the
+// client address. Only behind a trusted proxy, which must set or strip the
+// header, is the identity the proxy states in X-Actor used instead; from
+// anyone else it is just a claim.
+func (h *Handler) requestMetaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > maxRequestIDLength || strings.ContainsFunc(requestID, isControl) {
+			requestID = newRequestID()
+		}
+		var actor string
+		if h.config.HTTPServer.TrustProxy {
+			actor = strings.TrimSpace(r.Header.Get("X-Actor"))
+		}
+		if actor == "" || len(actor) > maxRequestIDLength || strings.ContainsFunc(actor, isControl) {
+			actor = "ip:" + h.clientIP(r)
+		}
+
+		w.Header().Set("X-Request-ID", requestID)
+		ctx := reqmeta.With(r.Context(), reqmeta.Meta{RequestID: requestID, Actor: actor})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// freshReadMiddleware serves reads of requests sent with
+// "Cache-Control: no-cache" from the primary database, so a client can
+// read back what it has just written before the replicas catch up.
+func freshReadMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+				r = r.WithContext(reqmeta.WithReadPrimary(r.Context()))
+				break
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
 func (h *Handler) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		h.logger.Info("Received request", "method", r.Method, "path", r.URL.Path)
+		requestID := reqmeta.From(r.Context()).RequestID
+		h.logger.Info("Received request", "method", r.Method, "path", r.URL.Path, "request_id", requestID)
 		next.ServeHTTP(w, r)
 		duration := time.Since(start)
-		h.logger.Info("Request completed", "method", r.Method, "path", r.URL.Path, "duration", duration)
+		h.logger.Info("Request completed", "method", r.Method, "path", r.URL.Path, "request_id", requestID, "duration", duration)
 	})
 }
+
+// rateLimitMiddleware rejects requests from an actor that has used up its
+// bucket with 429 and reports the actor's quota on every response. Buckets
+// are keyed by the actor requestMetaMiddleware found, so users behind a
+// trusted proxy each get their own rather than sharing the proxy's address.
+func (h *Handler) rateLimitMiddleware(limiter *ratelimit.Limiter, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := reqmeta.From(r.Context()).Actor
+		if key == "" {
+			key = "ip:" + h.clientIP(r)
+		}
+		d := limiter.Allow(key, time.Now())
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		w.Header().Set("RateLimit-Reset", seconds(d.Reset))
+		if !d.Allowed {
+			w.Header().Set("Retry-After", seconds(d.RetryAfter))
+			h.writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// clientIP takes the address the request came from. Behind a trusted proxy
+// it is the last X-Forwarded-For entry, the one the proxy itself appended;
+// earlier entries are supplied by the client and can be forged.
+func (h *Handler) clientIP(r *http.Request) string {
+	if h.config.HTTPServer.TrustProxy {
+		forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+		if ip := strings.TrimSpace(forwarded[len(forwarded)-1]); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/logger"
+	"pet-project/internal/ratelimit"
+	"pet-project/internal/reqmeta"
+)
+
+func TestActorHeaderNeedsTrustedProxy(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		headers    map[string]string
+		want       string
+	}{
+		{"no proxy", false, nil, "ip:192.0.2.1"},
+		{"forged actor", false, map[string]string{"X-Actor": "admin", "X-Forwarded-For": "198.51.100.7"}, "ip:192.0.2.1"},
+		{"proxy actor", true, map[string]string{"X-Actor": "alice", "X-Forwarded-For": "198.51.100.7"}, "alice"},
+		{"proxy without actor", true, map[string]string{"X-Forwarded-For": "198.51.100.7"}, "ip:198.51.100.7"},
+		{"proxy with bad actor", true, map[string]string{"X-Actor": "al\nice", "X-Forwarded-For": "198.51.100.7"}, "ip:198.51.100.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{HTTPServer: config.HTTPServer{TrustProxy: tt.trustProxy}}
+			h := NewHandler(fakeService{}, logger.New("test"), cfg)
+
+			var actor string
+			handler := h.requestMetaMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = reqmeta.From(r.Context()).Actor
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/products", nil)
+			req.RemoteAddr = "192.0.2.1:51234"
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if actor != tt.want {
+				t.Errorf("actor = %q, want %q", actor, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	h := newTestHandler()
+	// One request a minute with bursts of two.
+	handler := h.rateLimitMiddleware(ratelimit.New(1, time.Minute, 2), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.RemoteAddr = "192.0.2.1:51234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		rec := send()
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status %d, want %d", i+1, rec.Code, http.StatusNoContent)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != remaining {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %q", i+1, got, remaining)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "" {
+			t.Errorf("request %d: Retry-After = %q on an allowed request", i+1, got)
+		}
+	}
+
+	rec := send()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request 3: status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	// Durations are rounded up to whole seconds.
+	for name, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "120",
+		"Retry-After":         "60",
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error != "rate limit exceeded" {
+		t.Errorf("body = %s, want the rate limit error", rec.Body)
+	}
+}
+
+func TestRateLimitKeyedByActor(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		want       int
+	}{
+		// Behind the proxy every user has a bucket of their own.
+		{"trusted proxy", true, http.StatusNoContent},
+		// Otherwise X-Actor is ignored and the address is what counts.
+		{"no proxy", false, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{HTTPServer: config.HTTPServer{TrustProxy: tt.trustProxy}}
+			h := NewHandler(fakeService{}, logger.New("test"), cfg)
+			limited := h.rateLimitMiddleware(ratelimit.New(1, time.Minute, 1), func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+			handler := h.requestMetaMiddleware(limited)
+
+			var got int
+			for _, actor := range []string{"alice", "bob"} {
+				req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+				req.RemoteAddr = "192.0.2.1:51234"
+				req.Header.Set("X-Actor", actor)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				got = rec.Code
+			}
+			if got != tt.want {
+				t.Errorf("second actor from the same address: status %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,788 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas   map[string]openAPISchema   `json:"schemas"`
+		Responses map[string]openAPIResponse `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	RequestBody *openAPIResponse           `json:"requestBody"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Type       string                   `json:"type"`
+	Format     string                   `json:"format"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Items      *openAPISchema           `json:"items"`
+
+	AdditionalProperties bool `json:"additionalProperties"`
+}
+
+// specTypes binds every component schema to the Go type a handler decodes
+// or encodes for it.
+var specTypes = map[string]reflect.Type{
+	"CreateUserRequest":           reflect.TypeFor[CreateUserRequest](),
+	"UpdateUserRequest":           reflect.TypeFor[UpdateUserRequest](),
+	"UserResponse":                reflect.TypeFor[UserResponse](),
+	"UserPageResponse":            reflect.TypeFor[UserPageResponse](),
+	"CreateUserResponse":          reflect.TypeFor[CreateUserResponse](),
+	"CreateProductRequest":        reflect.TypeFor[CreateProductRequest](),
+	"UpdateProductRequest":        reflect.TypeFor[UpdateProductRequest](),
+	"ProductResponse":             reflect.TypeFor[ProductResponse](),
+	"ProductVersionResponse":      reflect.TypeFor[ProductVersionResponse](),
+	"ProductVersionListResponse":  reflect.TypeFor[ProductVersionListResponse](),
+	"ProductImportResponse":       reflect.TypeFor[ProductImportResponse](),
+	"ImportRowErrorResponse":      reflect.TypeFor[ImportRowErrorResponse](),
+	"OrderExportRowResponse":      reflect.TypeFor[OrderExportRowResponse](),
+	"RevenueRowResponse":          reflect.TypeFor[RevenueRowResponse](),
+	"RevenueReportResponse":       reflect.TypeFor[RevenueReportResponse](),
+	"ProductSalesResponse":        reflect.TypeFor[ProductSalesResponse](),
+	"TopProductsReportResponse":   reflect.TypeFor[TopProductsReportResponse](),
+	"CustomerSalesResponse":       reflect.TypeFor[CustomerSalesResponse](),
+	"TopCustomersReportResponse":  reflect.TypeFor[TopCustomersReportResponse](),
+	"StockAdjustmentRequest":      reflect.TypeFor[StockAdjustmentRequest](),
+	"CreateProductResponse":       reflect.TypeFor[CreateProductResponse](),
+	"LowStockResponse":            reflect.TypeFor[LowStockResponse](),
+	"StockMovementResponse":       reflect.TypeFor[StockMovementResponse](),
+	"StockMovementPageResponse":   reflect.TypeFor[StockMovementPageResponse](),
+	"StockDiscrepancyResponse":    reflect.TypeFor[StockDiscrepancyResponse](),
+	"StockReconciliationResponse": reflect.TypeFor[StockReconciliationResponse](),
+	"CreateOrderRequest":          reflect.TypeFor[CreateOrderRequest](),
+	"OrderItemRequest":            reflect.TypeFor[OrderItemRequest](),
+	"OrderResponse":               reflect.TypeFor[OrderResponse](),
+	"OrderLineResponse":           reflect.TypeFor[OrderLineResponse](),
+	"CreateOrderResponse":         reflect.TypeFor[CreateOrderResponse](),
+	"CreateWebhookRequest":        reflect.TypeFor[CreateWebhookRequest](),
+	"CreateWebhookResponse":       reflect.TypeFor[CreateWebhookResponse](),
+	"WebhookResponse":             reflect.TypeFor[WebhookResponse](),
+	"WebhookListResponse":         reflect.TypeFor[WebhookListResponse](),
+	"WebhookDeliveryResponse":     reflect.TypeFor[WebhookDeliveryResponse](),
+	"WebhookAttemptResponse":      reflect.TypeFor[WebhookAttemptResponse](),
+	"WebhookDeliveryPageResponse": reflect.TypeFor[WebhookDeliveryPageResponse](),
+	"CreatePromoCodeRequest":      reflect.TypeFor[CreatePromoCodeRequest](),
+	"PromoCodeResponse":           reflect.TypeFor[PromoCodeResponse](),
+	"PromoCodeListResponse":       reflect.TypeFor[PromoCodeListResponse](),
+	"AuditEntryResponse":          reflect.TypeFor[AuditEntryResponse](),
+	"AuditPageResponse":           reflect.TypeFor[AuditPageResponse](),
+	"ErrorResponse":               reflect.TypeFor[ErrorResponse](),
+}
+
+// specFixtures describes a request that drives each operation to its
+// documented success response.
+var specFixtures = map[string]struct {
+	path    string
+	headers map[string]string
+	body    any
+	status  int
+}{
+	"createUser": {
+		path:   "/users",
+		body:   CreateUserRequest{FirstName: "Ivan", LastName: "Petrov", Age: 30, Password: "password123"},
+		status: http.StatusCreated,
+	},
+	"getUserByID": {
+		path:   "/users/1",
+		status: http.StatusOK,
+	},
+	"listUsers": {
+		path:   "/users?q=iv&is_married=false&sort=-age&limit=10",
+		status: http.StatusOK,
+	},
+	"updateUser": {
+		path:    "/users/1",
+		headers: map[string]string{"If-Match": `"1"`},
+		body:    UpdateUserRequest{IsMarried: ptr(true)},
+		status:  http.StatusOK,
+	},
+	"deleteUser": {
+		path:   "/users/1",
+		status: http.StatusNoContent,
+	},
+	"createProduct": {
+		path:   "/products",
+		body:   CreateProductRequest{Description: "Tea", Tags: []string{"drinks"}, Quantity: 10, Price: 4.5},
+		status: http.StatusCreated,
+	},
+	"getProductByID": {
+		path:   "/products/1",
+		status: http.StatusOK,
+	},
+	"updateProduct": {
+		path:    "/products/1",
+		headers: map[string]string{"If-Match": `"1"`},
+		body:    UpdateProductRequest{Price: ptr(5.0)},
+		status:  http.StatusOK,
+	},
+	"adjustProductStock": {
+		path:   "/products/1/stock-adjustments",
+		body:   StockAdjustmentRequest{Delta: 5, Reason: "restock"},
+		status: http.StatusOK,
+	},
+	"listLowStockProducts": {
+		path:   "/products/low-stock",
+		status: http.StatusOK,
+	},
+	"importProducts": {
+		path:    "/products:bulk?dry_run=true",
+		headers: map[string]string{"Content-Type": "text/csv"},
+		body:    "sku,description,tags,quantity,price\nTEA-1,Tea,drinks|hot,10,4.5\n",
+		status:  http.StatusOK,
+	},
+	"listProductVersions": {
+		path:   "/products/1/versions",
+		status: http.StatusOK,
+	},
+	"listStockMovements": {
+		path:   "/products/1/stock-movements?limit=10",
+		status: http.StatusOK,
+	},
+	"reconcileStock": {
+		path:   "/inventory/reconciliation",
+		status: http.StatusOK,
+	},
+	"createOrder": {
+		path:   "/orders",
+		body:   CreateOrderRequest{UserID: 1, Items: []OrderItemRequest{{ProductID: 1, Quantity: 2}}, PromoCode: "SPRING10"},
+		status: http.StatusCreated,
+	},
+	"exportOrders": {
+		path:   "/orders/export?from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&format=csv",
+		status: http.StatusOK,
+	},
+	"revenueReport": {
+		path:   "/reports/revenue?from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&period=week&tz=Europe/Moscow",
+		status: http.StatusOK,
+	},
+	"topProductsReport": {
+		path:   "/reports/top-products?from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&limit=5",
+		status: http.StatusOK,
+	},
+	"topCustomersReport": {
+		path:   "/reports/top-customers?from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&format=csv",
+		status: http.StatusOK,
+	},
+	"getOrderByID": {
+		path:   "/orders/1",
+		status: http.StatusOK,
+	},
+	"cancelOrder": {
+		path:   "/orders/1/cancel",
+		status: http.StatusOK,
+	},
+	"createPromoCode": {
+		path:   "/promo-codes",
+		body:   CreatePromoCodeRequest{Code: "SPRING10", Kind: domain.PromoPercent, Value: 10, Tags: []string{"drinks"}},
+		status: http.StatusCreated,
+	},
+	"listPromoCodes": {
+		path:   "/promo-codes",
+		status: http.StatusOK,
+	},
+	"getPromoCode": {
+		path:   "/promo-codes/1",
+		status: http.StatusOK,
+	},
+	"createWebhook": {
+		path:   "/webhooks",
+		body:   CreateWebhookRequest{URL: "https://partner.example/hooks", EventTypes: []string{domain.EventOrderCreated}},
+		status: http.StatusCreated,
+	},
+	"listWebhooks": {
+		path:   "/webhooks",
+		status: http.StatusOK,
+	},
+	"getWebhook": {
+		path:   "/webhooks/1",
+		status: http.StatusOK,
+	},
+	"deleteWebhook": {
+		path:   "/webhooks/1",
+		status: http.StatusNoContent,
+	},
+	"listWebhookDeliveries": {
+		path:   "/webhooks/1/deliveries?limit=10",
+		status: http.StatusOK,
+	},
+	"getWebhookDelivery": {
+		path:   "/webhooks/1/deliveries/2",
+		status: http.StatusOK,
+	},
+	"redeliverWebhook": {
+		path:   "/webhooks/1/deliveries/2/redeliver",
+		status: http.StatusAccepted,
+	},
+	"listAuditEntries": {
+		path:   "/admin/audit?entity=product&entity_id=1&from=2026-01-01T00:00:00Z&limit=10",
+		status: http.StatusOK,
+	},
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type fakeService struct{}
+
+func (fakeService) CreateUser(ctx context.Context, user domain.User) (int64, error) {
+	return 1, nil
+}
+
+func (fakeService) GetUserByID(ctx context.Context, id int64) (domain.User, error) {
+	return domain.User{ID: id, FirstName: "Ivan", LastName: "Petrov", FullName: "Ivan Petrov", Age: 30, Password: "password123", Version: 1}, nil
+}
+
+func (f fakeService) UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error) {
+	return f.GetUserByID(ctx, id)
+}
+
+func (fakeService) DeleteUser(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (f fakeService) ListUsers(ctx context.Context, filter domain.UserFilter, cursor string) (domain.UserPage, error) {
+	user, _ := f.GetUserByID(ctx, 1)
+	return domain.UserPage{Users: []domain.User{user}, NextCursor: "next"}, nil
+}
+
+func (fakeService) CreateProduct(ctx context.Context, product domain.Product) (int64, error) {
+	return 1, nil
+}
+
+func (fakeService) GetProductByID(ctx context.Context, id int64) (domain.Product, error) {
+	return domain.Product{ID: id, Description: "Tea", Tags: []string{"drinks"}, Quantity: 10, Price: 4.5, ReorderThreshold: ptr(5), TaxClass: "standard", Version: 1}, nil
+}
+
+func (f fakeService) GetProductAt(ctx context.Context, id int64, at time.Time) (domain.Product, error) {
+	return f.GetProductByID(ctx, id)
+}
+
+func (fakeService) ImportProducts(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, error) {
+	return domain.ProductImportResult{DryRun: opts.DryRun, Rows: len(rows), Created: len(rows)}, nil
+}
+
+func (fakeService) ListProductVersions(ctx context.Context, id int64) (domain.ProductVersionList, error) {
+	return domain.ProductVersionList{Versions: []domain.ProductVersion{
+		{ProductID: id, Version: 1, Description: "Tea", Tags: []string{"drinks"}, Price: 4.5, TaxClass: "standard", ReorderThreshold: ptr(5), ValidFrom: time.Now()},
+	}}, nil
+}
+
+func (f fakeService) UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error) {
+	return f.GetProductByID(ctx, id)
+}
+
+func (f fakeService) AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error) {
+	return f.GetProductByID(ctx, id)
+}
+
+func (f fakeService) ListLowStockProducts(ctx context.Context) ([]domain.Product, error) {
+	product, _ := f.GetProductByID(ctx, 1)
+	return []domain.Product{product}, nil
+}
+
+func (fakeService) ListStockMovements(ctx context.Context, productID int64, cursor string, limit int) (domain.StockMovementPage, error) {
+	orderID := int64(1)
+	return domain.StockMovementPage{
+		Movements: []domain.StockMovement{
+			{ID: 2, ProductID: productID, Kind: domain.MovementOrder, Delta: -2, QuantityAfter: 8, Reason: "order 1", OrderID: &orderID, CreatedAt: time.Now()},
+		},
+		NextCursor: "2",
+	}, nil
+}
+
+func (fakeService) ReconcileStock(ctx context.Context) (domain.StockReconciliation, error) {
+	return domain.StockReconciliation{
+		Discrepancies: []domain.StockDiscrepancy{{ProductID: 1, Quantity: 8, LedgerTotal: 10}},
+	}, nil
+}
+
+func (fakeService) CreateOrder(ctx context.Context, order domain.NewOrder) (int64, error) {
+	return 1, nil
+}
+
+func (fakeService) GetOrderByID(ctx context.Context, id int64) (domain.Order, error) {
+	now := time.Now()
+	return domain.Order{
+		ID:          id,
+		UserID:      1,
+		Status:      domain.OrderStatusCancelled,
+		CreatedAt:   now,
+		CancelledAt: &now,
+		Subtotal:    9,
+		Discount:    0.9,
+		PromoCode:   "SPRING10",
+		NetTotal:    8.1,
+		TaxTotal:    1.62,
+		TotalPrice:  9.72,
+		OrderProduct: []domain.OrderProduct{
+			{OrderID: id, ProductID: 1, ProductVersion: 1, Description: "Tea", Tags: []string{"drinks"}, Quantity: 2, Price: 4.5, Discount: 0.9, TaxClass: "standard", TaxRate: 0.2, TaxAmount: 1.62},
+		},
+	}, nil
+}
+
+func (f fakeService) CancelOrder(ctx context.Context, id int64) (domain.Order, error) {
+	return f.GetOrderByID(ctx, id)
+}
+
+func (fakeService) ExportOrders(ctx context.Context, from, to time.Time, emit func(domain.OrderExportRow) error) error {
+	return emit(domain.OrderExportRow{
+		OrderID: 1, UserID: 1, CreatedAt: from, Status: domain.OrderStatusCreated,
+		ProductID: 1, ProductVersion: 1, Description: "Tea", Quantity: 2, Price: 4.5, OrderTotal: 9,
+	})
+}
+
+func (fakeService) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	promo.ID = 1
+	promo.CreatedAt = time.Now()
+	return promo, nil
+}
+
+func (fakeService) GetPromoCode(ctx context.Context, id int64) (domain.PromoCode, error) {
+	until := time.Now().Add(24 * time.Hour)
+	return domain.PromoCode{
+		ID:             id,
+		Code:           "SPRING10",
+		Kind:           domain.PromoPercent,
+		Value:          10,
+		ValidUntil:     &until,
+		MaxUses:        ptr(100),
+		MaxUsesPerUser: ptr(1),
+		ProductIDs:     []int64{1},
+		Tags:           []string{"drinks"},
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+func (f fakeService) ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error) {
+	promo, _ := f.GetPromoCode(ctx, 1)
+	return []domain.PromoCode{promo}, nil
+}
+
+func (fakeService) CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	sub.ID = 1
+	sub.Secret = "0123456789abcdef0123456789abcdef"
+	sub.CreatedAt = time.Now()
+	return sub, nil
+}
+
+func (fakeService) GetWebhook(ctx context.Context, id int64) (domain.WebhookSubscription, error) {
+	return domain.WebhookSubscription{ID: id, URL: "https://partner.example/hooks", EventTypes: []string{domain.EventOrderCreated}, CreatedAt: time.Now()}, nil
+}
+
+func (f fakeService) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	sub, _ := f.GetWebhook(ctx, 1)
+	return []domain.WebhookSubscription{sub}, nil
+}
+
+func (fakeService) DeleteWebhook(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (fakeService) GetWebhookDelivery(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error) {
+	now := time.Now()
+	return domain.WebhookDelivery{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		EventID:        1,
+		EventType:      domain.EventOrderCreated,
+		Status:         domain.DeliveryPending,
+		Attempts:       1,
+		ResponseCode:   ptr(503),
+		LastError:      ptr("subscriber responded with status 503"),
+		NextAttemptAt:  now.Add(time.Minute),
+		LastAttemptAt:  &now,
+		CreatedAt:      now,
+		Log: []domain.WebhookAttempt{
+			{AttemptedAt: now, ResponseCode: ptr(503), Error: "subscriber responded with status 503", DurationMS: 12},
+		},
+	}, nil
+}
+
+func (f fakeService) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, cursor string, limit int) (domain.WebhookDeliveryPage, error) {
+	delivery, _ := f.GetWebhookDelivery(ctx, subscriptionID, 2)
+	delivery.Log = nil
+	return domain.WebhookDeliveryPage{Deliveries: []domain.WebhookDelivery{delivery}, NextCursor: "2"}, nil
+}
+
+func (f fakeService) RedeliverWebhook(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error) {
+	return f.GetWebhookDelivery(ctx, subscriptionID, id)
+}
+
+func (fakeService) ListAuditEntries(ctx context.Context, filter domain.AuditFilter, cursor string) (domain.AuditPage, error) {
+	return domain.AuditPage{
+		Entries: []domain.AuditEntry{{
+			ID:        3,
+			Actor:     "ip:192.0.2.1",
+			RequestID: "4bf92f3577b34da6",
+			Entity:    domain.AggregateProduct,
+			EntityID:  1,
+			Action:    domain.AuditUpdate,
+			Before:    map[string]any{"price": 4.5, "version": 1},
+			After:     map[string]any{"price": 5.0, "version": 2},
+			CreatedAt: time.Now(),
+		}},
+		NextCursor: "3",
+	}, nil
+}
+
+func loadSpec(t *testing.T) openAPIDoc {
+	t.Helper()
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return doc
+}
+
+func newTestHandler() *Handler {
+	return NewHandler(fakeService{}, logger.New("test"), &config.Config{})
+}
+
+func (doc openAPIDoc) resolveResponse(resp openAPIResponse) openAPIResponse {
+	if name, ok := strings.CutPrefix(resp.Ref, "#/components/responses/"); ok {
+		return doc.Components.Responses[name]
+	}
+	return resp
+}
+
+func (doc openAPIDoc) resolveSchema(schema openAPISchema) (string, openAPISchema) {
+	if name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/"); ok {
+		return name, doc.Components.Schemas[name]
+	}
+	return "", schema
+}
+
+func TestSpecSchemasMatchGoTypes(t *testing.T) {
+	doc := loadSpec(t)
+
+	for name := range doc.Components.Schemas {
+		if _, ok := specTypes[name]; !ok {
+			t.Errorf("schema %s has no Go type in specTypes", name)
+		}
+	}
+
+	for name, typ := range specTypes {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("Go type %s has no schema %s in openapi.json", typ, name)
+			continue
+		}
+		compareSchema(t, doc, name, schema, typ)
+	}
+}
+
+func compareSchema(t *testing.T, doc openAPIDoc, at string, schema openAPISchema, typ reflect.Type) {
+	t.Helper()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if ref, _ := doc.resolveSchema(schema); ref != "" {
+		if specTypes[ref] != typ {
+			t.Errorf("%s: schema %s is bound to %s, field has type %s", at, ref, specTypes[ref], typ)
+		}
+		return
+	}
+
+	want := jsonType(typ)
+	if schema.Type != want {
+		t.Errorf("%s: spec type %q, Go type %s encodes as %q", at, schema.Type, typ, want)
+		return
+	}
+
+	switch want {
+	case "array":
+		if schema.Items == nil {
+			t.Errorf("%s: array schema has no items", at)
+			return
+		}
+		compareSchema(t, doc, at+"[]", *schema.Items, typ.Elem())
+	case "object":
+		if typ.Kind() != reflect.Struct {
+			return
+		}
+		fields := jsonFields(typ)
+		for name, field := range fields {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				t.Errorf("%s: field %q is missing from the spec", at, name)
+				continue
+			}
+			compareSchema(t, doc, at+"."+name, prop, field)
+		}
+		for name := range schema.Properties {
+			if _, ok := fields[name]; !ok {
+				t.Errorf("%s: spec property %q has no Go field", at, name)
+			}
+		}
+	}
+}
+
+func jsonType(typ reflect.Type) string {
+	if typ == reflect.TypeFor[time.Time]() {
+		return "string"
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// undocumentedPaths are served by the API but describe it rather than
+// belong to it.
+var undocumentedPaths = map[string]bool{
+	"/openapi.json": true,
+	"/docs":         true,
+	"/docs.js":      true,
+}
+
+func TestSpecOperationsAreRouted(t *testing.T) {
+	doc := loadSpec(t)
+	h := newTestHandler()
+
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			method = strings.ToUpper(method)
+			fixture, ok := specFixtures[op.OperationID]
+			if !ok {
+				t.Errorf("%s %s: operation %q has no fixture", method, path, op.OperationID)
+				continue
+			}
+			req := httptest.NewRequest(method, fixture.path, nil)
+			if _, pattern := h.mux.Handler(req); pattern != method+" "+path {
+				t.Errorf("%s %s: routed to pattern %q", method, path, pattern)
+			}
+		}
+	}
+
+	for _, rt := range h.routes {
+		if undocumentedPaths[rt.path] {
+			continue
+		}
+		if _, ok := doc.Paths[rt.path][strings.ToLower(rt.method)]; !ok {
+			t.Errorf("route %s %s is missing from openapi.json", rt.method, rt.path)
+		}
+	}
+}
+
+func TestHandlersMatchSpec(t *testing.T) {
+	doc := loadSpec(t)
+	h := newTestHandler()
+
+	ids := make([]string, 0, len(specFixtures))
+	for id := range specFixtures {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	operations := make(map[string]struct {
+		method string
+		op     openAPIOperation
+	})
+	for _, ops := range doc.Paths {
+		for method, op := range ops {
+			operations[op.OperationID] = struct {
+				method string
+				op     openAPIOperation
+			}{strings.ToUpper(method), op}
+		}
+	}
+
+	for _, id := range ids {
+		t.Run(id, func(t *testing.T) {
+			fixture := specFixtures[id]
+			entry, ok := operations[id]
+			if !ok {
+				t.Fatalf("fixture %q has no operation in openapi.json", id)
+			}
+
+			var body bytes.Buffer
+			if raw, ok := fixture.body.(string); ok {
+				// Non-JSON bodies are sent as they are, with their Content-Type.
+				contentType := fixture.headers["Content-Type"]
+				if entry.op.RequestBody == nil || entry.op.RequestBody.Content[contentType].Schema.Type != "string" {
+					t.Fatalf("spec declares no %s request body", contentType)
+				}
+				body.WriteString(raw)
+			} else if fixture.body != nil {
+				if entry.op.RequestBody == nil {
+					t.Fatalf("fixture sends a body, spec declares none")
+				}
+				name, _ := doc.resolveSchema(entry.op.RequestBody.Content["application/json"].Schema)
+				if got := specTypes[name]; got != reflect.TypeOf(fixture.body) {
+					t.Fatalf("request body schema %s is bound to %s, handler decodes %T", name, got, fixture.body)
+				}
+				if err := json.NewEncoder(&body).Encode(fixture.body); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(entry.method, fixture.path, &body)
+			for key, value := range fixture.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			h.mux.ServeHTTP(rec, req)
+
+			if rec.Code != fixture.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, fixture.status, rec.Body.String())
+			}
+
+			resp, ok := entry.op.Responses[strconv.Itoa(rec.Code)]
+			if !ok {
+				t.Fatalf("status %d is not documented", rec.Code)
+			}
+			resp = doc.resolveResponse(resp)
+			contentType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+			if contentType != "application/json" {
+				if _, ok := resp.Content[contentType]; len(resp.Content) > 0 && !ok {
+					t.Fatalf("content type %q is not documented", contentType)
+				}
+				return
+			}
+			media, ok := resp.Content["application/json"]
+			if !ok {
+				return
+			}
+
+			var got any
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			checkValue(t, doc, "response", media.Schema, got)
+		})
+	}
+}
+
+func checkValue(t *testing.T, doc openAPIDoc, at string, schema openAPISchema, value any) {
+	t.Helper()
+	_, schema = doc.resolveSchema(schema)
+	if value == nil {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if schema.Type != "object" {
+			t.Errorf("%s: got object, spec type %q", at, schema.Type)
+			return
+		}
+		for key, field := range v {
+			prop, ok := schema.Properties[key]
+			if !ok && schema.AdditionalProperties {
+				continue
+			}
+			if !ok {
+				t.Errorf("%s: field %q is not in the spec", at, key)
+				continue
+			}
+			checkValue(t, doc, at+"."+key, prop, field)
+		}
+	case []any:
+		if schema.Type != "array" || schema.Items == nil {
+			t.Errorf("%s: got array, spec type %q", at, schema.Type)
+			return
+		}
+		for _, item := range v {
+			checkValue(t, doc, at+"[]", *schema.Items, item)
+		}
+	case string:
+		if schema.Type != "string" {
+			t.Errorf("%s: got string, spec type %q", at, schema.Type)
+		}
+	case bool:
+		if schema.Type != "boolean" {
+			t.Errorf("%s: got boolean, spec type %q", at, schema.Type)
+		}
+	case float64:
+		if schema.Type != "number" && schema.Type != "integer" {
+			t.Errorf("%s: got number, spec type %q", at, schema.Type)
+		}
+	}
+}
+
+func (fakeService) RevenueReport(ctx context.Context, filter domain.ReportFilter) (domain.RevenueReport, error) {
+	return domain.RevenueReport{
+		From: filter.From, To: filter.To, Period: filter.Period, TimeZone: filter.TimeZone,
+		Rows: []domain.RevenueRow{{PeriodStart: "2025-12-29", Orders: 1, Items: 2, GrossSales: 9, Discount: 0.9, Revenue: 8.1, Tax: 1.62}},
+	}, nil
+}
+
+func (fakeService) TopProductsReport(ctx context.Context, filter domain.ReportFilter) (domain.TopProductsReport, error) {
+	return domain.TopProductsReport{
+		From: filter.From, To: filter.To,
+		Products: []domain.ProductSales{{ProductID: 1, Description: "Tea", Quantity: 2, Orders: 1, Revenue: 8.1}},
+	}, nil
+}
+
+func (fakeService) TopCustomersReport(ctx context.Context, filter domain.ReportFilter) (domain.TopCustomersReport, error) {
+	return domain.TopCustomersReport{
+		From: filter.From, To: filter.To,
+		Customers: []domain.CustomerSales{{UserID: 1, FirstName: "Ivan", LastName: "Petrov", Orders: 1, Items: 2, Revenue: 8.1}},
+	}, nil
+}
+
+func (fakeService) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pet-project/internal/validation"
+)
+
+func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	order := req.toDomain()
+	if err := validation.ValidateCreateOrder(order); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.service.CreateOrder(r.Context(), order)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	response := CreateOrderResponse{ID: id}
+	h.writeJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, err := h.service.GetOrderByID(r.Context(), id)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, orderResponse(order))
+}
+
+func (h *Handler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, err := h.service.CancelOrder(r.Context(), id)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, orderResponse(order))
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pet-project/internal/service"
+)
+
+const defaultPageLimit = 20
+
+func pathInt64(r *http.Request, name string) (int64, error) {
+	value := r.PathValue(name)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.Join(service.ErrValidation, fmt.Errorf("invalid path parameter %s: %q", name, value))
+	}
+	return id, nil
+}
+
+func queryInt(r *http.Request, name string) (*int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, errors.Join(service.ErrValidation, fmt.Errorf("invalid query parameter %s: %q", name, value))
+	}
+	return &n, nil
+}
+
+func queryInt64(r *http.Request, name string) (*int64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, errors.Join(service.ErrValidation, fmt.Errorf("invalid query parameter %s: %q", name, value))
+	}
+	return &n, nil
+}
+
+func queryBool(r *http.Request, name string) (*bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errors.Join(service.ErrValidation, fmt.Errorf("invalid query parameter %s: %q", name, value))
+	}
+	return &b, nil
+}
+
+func queryLimit(r *http.Request) (int, error) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return 0, err
+	}
+	if limit == nil {
+		return defaultPageLimit, nil
+	}
+	return *limit, nil
+}
+
+func queryTime(r *http.Request, name string) (*time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.Join(service.ErrValidation, fmt.Errorf("invalid query parameter %s: %q, want RFC 3339", name, value))
+	}
+	return &t, nil
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/importer"
+	"pet-project/internal/validation"
+)
+
+func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var req CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	product := req.toDomain()
+	if err := validation.ValidateCreateProduct(product); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.service.CreateProduct(r.Context(), product)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	response := CreateProductResponse{ID: id}
+	h.writeJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) GetProductByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	at, err := queryTime(r, "at")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// A past version cannot be updated, so it gets no ETag.
+	if at != nil {
+		product, err := h.service.GetProductAt(r.Context(), id, *at)
+		if err != nil {
+			h.ServiceError(w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, productResponse(product))
+		return
+	}
+
+	product, err := h.service.GetProductByID(r.Context(), id)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	h.writeJSON(w, http.StatusOK, productResponse(product))
+}
+
+func (h *Handler) ListProductVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	versions, err := h.service.ListProductVersions(r.Context(), id)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, productVersionListResponse(versions))
+}
+
+func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		h.preconditionError(w, err)
+		return
+	}
+
+	var req UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	update := req.toDomain()
+	if err := validation.ValidateUpdateProduct(update); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := h.service.UpdateProduct(r.Context(), id, version, update)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	h.writeJSON(w, http.StatusOK, productResponse(product))
+}
+
+func (h *Handler) AdjustProductStock(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	adjustment := req.toDomain()
+	if err := validation.ValidateStockAdjustment(adjustment); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := h.service.AdjustProductStock(r.Context(), id, adjustment)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	h.writeJSON(w, http.StatusOK, productResponse(product))
+}
+
+func (h *Handler) ListStockMovements(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := queryLimit(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validation.ValidateLimit(limit); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListStockMovements(r.Context(), id, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, stockMovementPageResponse(page))
+}
+
+func (h *Handler) ReconcileStock(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.ReconcileStock(r.Context())
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, stockReconciliationResponse(report))
+}
+
+func (h *Handler) ListLowStockProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.ListLowStockProducts(r.Context())
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, LowStockResponse{Products: productResponses(products)})
+}
+
+const maxImportBytes = 32 << 20
+
+var importContentTypes = map[string]string{
+	"text/csv":             domain.FormatCSV,
+	"application/x-ndjson": domain.FormatNDJSON,
+	"application/ndjson":   domain.FormatNDJSON,
+}
+
+// ImportProducts takes a CSV or NDJSON file as the request body. The format
+// comes from the format query parameter or else the Content-Type. A report
+// with row errors is returned as 422 since nothing was imported.
+func (h *Handler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = importContentTypes[mediaType]
+	}
+	if format == "" {
+		h.writeError(w, http.StatusBadRequest, "format must be given as a query parameter or Content-Type: text/csv or application/x-ndjson")
+		return
+	}
+
+	var opts domain.ProductImportOptions
+	for name, option := range map[string]*bool{"dry_run": &opts.DryRun, "upsert": &opts.Upsert} {
+		value, err := queryBool(r, name)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if value != nil {
+			*option = *value
+		}
+	}
+
+	rows, report, err := importer.ReadProducts(http.MaxBytesReader(w, r.Body, maxImportBytes), format, opts)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(report.Errors) > 0 {
+		h.writeJSON(w, http.StatusUnprocessableEntity, productImportResponse(report))
+		return
+	}
+
+	result, err := h.service.ImportProducts(r.Context(), rows, opts)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+	if len(result.Errors) > 0 {
+		h.writeJSON(w, http.StatusUnprocessableEntity, productImportResponse(result))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, productImportResponse(result))
+}
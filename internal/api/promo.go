@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pet-project/internal/validation"
+)
+
+func (h *Handler) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	var req CreatePromoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	promo := req.toDomain()
+	if err := validation.ValidateCreatePromoCode(promo); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	promo, err := h.service.CreatePromoCode(r.Context(), promo)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, promoCodeResponse(promo))
+}
+
+func (h *Handler) ListPromoCodes(w http.ResponseWriter, r *http.Request) {
+	promos, err := h.service.ListPromoCodes(r.Context())
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, promoCodeListResponse(promos))
+}
+
+func (h *Handler) GetPromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	promo, err := h.service.GetPromoCode(r.Context(), id)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, promoCodeResponse(promo))
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+	"pet-project/internal/validation"
+)
+
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+)
+
+func (h *Handler) RevenueReport(w http.ResponseWriter, r *http.Request) {
+	filter, format, err := reportFilter(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Period = r.URL.Query().Get("period")
+	if filter.Period == "" {
+		filter.Period = domain.ReportPeriodDay
+	}
+	filter.TimeZone = r.URL.Query().Get("tz")
+	if filter.TimeZone == "" {
+		filter.TimeZone = "UTC"
+	}
+	if err := validation.ValidateRevenueReport(filter); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.RevenueReport(r.Context(), filter)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	if format == reportFormatJSON {
+		h.writeJSON(w, http.StatusOK, revenueReportResponse(report))
+		return
+	}
+	records := [][]string{{"period_start", "orders", "items", "gross_sales", "discount", "revenue", "tax"}}
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			row.PeriodStart,
+			strconv.Itoa(row.Orders),
+			strconv.Itoa(row.Items),
+			money(row.GrossSales),
+			money(row.Discount),
+			money(row.Revenue),
+			money(row.Tax),
+		})
+	}
+	h.writeCSV(w, "revenue", filter, records)
+}
+
+func (h *Handler) TopProductsReport(w http.ResponseWriter, r *http.Request) {
+	filter, format, err := topReportFilter(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.TopProductsReport(r.Context(), filter)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	if format == reportFormatJSON {
+		h.writeJSON(w, http.StatusOK, topProductsReportResponse(report))
+		return
+	}
+	records := [][]string{{"product_id", "description", "quantity", "orders", "revenue"}}
+	for _, p := range report.Products {
+		records = append(records, []string{
+			strconv.FormatInt(p.ProductID, 10),
+			p.Description,
+			strconv.Itoa(p.Quantity),
+			strconv.Itoa(p.Orders),
+			money(p.Revenue),
+		})
+	}
+	h.writeCSV(w, "top-products", filter, records)
+}
+
+func (h *Handler) TopCustomersReport(w http.ResponseWriter, r *http.Request) {
+	filter, format, err := topReportFilter(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.TopCustomersReport(r.Context(), filter)
+	if err != nil {
+		h.ServiceError(w, err)
+		return
+	}
+
+	if format == reportFormatJSON {
+		h.writeJSON(w, http.StatusOK, topCustomersReportResponse(report))
+		return
+	}
+	records := [][]string{{"user_id", "first_name", "last_name", "orders", "items", "revenue"}}
+	for _, c := range report.Customers {
+		records = append(records, []string{
+			strconv.FormatInt(c.UserID, 10),
+			c.FirstName,
+			c.LastName,
+			strconv.Itoa(c.Orders),
+			strconv.Itoa(c.Items),
+			money(c.Revenue),
+		})
+	}
+	h.writeCSV(w, "top-customers", filter, records)
+}
+
+// reportFilter reads the range and output format every report takes.
+func reportFilter(r *http.Request) (domain.ReportFilter, string, error) {
+	from, err := queryTime(r, "from")
+	if err != nil {
+		return domain.ReportFilter{}, "", err
+	}
+	to, err := queryTime(r, "to")
+	if err != nil {
+		return domain.ReportFilter{}, "", err
+	}
+	if err := validation.ValidateTimeRange(from, to); err != nil {
+		return domain.ReportFilter{}, "", err
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = reportFormatJSON
+	case reportFormatJSON, reportFormatCSV:
+	default:
+		return domain.ReportFilter{}, "", errors.Join(service.ErrValidation, errors.New("format must be json or csv"))
+	}
+	return domain.ReportFilter{From: *from, To: *to}, format, nil
+}
+
+func topReportFilter(r *http.Request) (domain.ReportFilter, string, error) {
+	filter, format, err := reportFilter(r)
+	if err != nil {
+		return filter, format, err
+	}
+	if filter.Limit, err = queryLimit(r); err != nil {
+		return filter, format, err
+	}
+	return filter, format, validation.ValidateLimit(filter.Limit)
+}
+
+func (h *Handler) writeCSV(w http.ResponseWriter, name string, filter domain.ReportFilter, records [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", attachment(name, filter.From, filter.To, reportFormatCSV))
+	w.WriteHeader(http.StatusOK)
+	if err := csv.NewWriter(w).WriteAll(records); err != nil {
+		h.logger.Error(err, "Failed to write CSV response")
+	}
+}
@@ -4,21 +4,41 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"pet-project/internal/config"
 	"pet-project/internal/logger"
+	"pet-project/internal/ratelimit"
 	"pet-project/internal/service"
 )
 
 type Handler struct {
-	service service.UserService
+	service service.Service
 	logger  *logger.Logger
 	config  *config.Config
 	mux     *http.ServeMux
+	routes  []route
 }
 
-func NewHandler(service service.UserService, logger *logger.Logger, config *config.Config) *Handler {
+var standardMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+}
+
+func NewHandler(service service.Service, logger *logger.Logger, config *config.Config) *Handler {
 	h := &Handler{
 		service: service,
 		logger:  logger,
@@ -32,7 +52,7 @@ func NewHandler(service service.UserService, logger *logger.Logger, config *conf
 func (h *Handler) StartServer(ctx context.Context) error {
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", h.config.HTTPServer.Address, h.config.HTTPServer.Port),
-		Handler:      h.loggingMiddleware(h.mux),
+		Handler:      h.requestMetaMiddleware(h.loggingMiddleware(freshReadMiddleware(h.mux))),
 		ReadTimeout:  h.config.HTTPServer.Timeout,
 		WriteTimeout: h.config.HTTPServer.Timeout,
 		IdleTimeout:  h.config.HTTPServer.IdleTimeout,
@@ -61,6 +81,106 @@ func (h *Handler) StartServer(ctx context.Context) error {
 }
 
 func (h *Handler) setupRoutes() {
-	h.mux.HandleFunc("/users", h.CreateUser)
-	h.mux.HandleFunc("/users/", h.GetUserByID)
+	h.routes = []route{
+		{http.MethodPost, "/users", h.CreateUser},
+		{http.MethodGet, "/users", h.ListUsers},
+		{http.MethodGet, "/users/{id}", h.GetUserByID},
+		{http.MethodPatch, "/users/{id}", h.UpdateUser},
+		{http.MethodDelete, "/users/{id}", h.DeleteUser},
+		{http.MethodPost, "/products", h.CreateProduct},
+		{http.MethodPost, "/products:bulk", h.ImportProducts},
+		{http.MethodGet, "/products/low-stock", h.ListLowStockProducts},
+		{http.MethodGet, "/products/{id}", h.GetProductByID},
+		{http.MethodPatch, "/products/{id}", h.UpdateProduct},
+		{http.MethodGet, "/products/{id}/versions", h.ListProductVersions},
+		{http.MethodPost, "/products/{id}/stock-adjustments", h.AdjustProductStock},
+		{http.MethodGet, "/products/{id}/stock-movements", h.ListStockMovements},
+		{http.MethodGet, "/inventory/reconciliation", h.ReconcileStock},
+		{http.MethodPost, "/orders", h.CreateOrder},
+		{http.MethodGet, "/orders/export", h.ExportOrders},
+		{http.MethodGet, "/reports/revenue", h.RevenueReport},
+		{http.MethodGet, "/reports/top-products", h.TopProductsReport},
+		{http.MethodGet, "/reports/top-customers", h.TopCustomersReport},
+		{http.MethodGet, "/orders/{id}", h.GetOrderByID},
+		{http.MethodPost, "/orders/{id}/cancel", h.CancelOrder},
+		{http.MethodPost, "/promo-codes", h.CreatePromoCode},
+		{http.MethodGet, "/promo-codes", h.ListPromoCodes},
+		{http.MethodGet, "/promo-codes/{id}", h.GetPromoCode},
+		{http.MethodPost, "/webhooks", h.CreateWebhook},
+		{http.MethodGet, "/webhooks", h.ListWebhooks},
+		{http.MethodGet, "/webhooks/{id}", h.GetWebhook},
+		{http.MethodDelete, "/webhooks/{id}", h.DeleteWebhook},
+		{http.MethodGet, "/webhooks/{id}/deliveries", h.ListWebhookDeliveries},
+		{http.MethodGet, "/webhooks/{id}/deliveries/{deliveryID}", h.GetWebhookDelivery},
+		{http.MethodPost, "/webhooks/{id}/deliveries/{deliveryID}/redeliver", h.RedeliverWebhook},
+		{http.MethodGet, "/admin/audit", h.ListAuditEntries},
+		{http.MethodGet, "/openapi.json", h.OpenAPISpec},
+		{http.MethodGet, "/docs", h.Docs},
+		{http.MethodGet, "/docs.js", h.DocsScript},
+	}
+
+	allowed := make(map[string][]string)
+	var paths []string
+	for name := range h.config.RateLimit.Routes {
+		if !slices.ContainsFunc(h.routes, func(rt route) bool { return rt.method+" "+rt.path == name }) {
+			h.logger.Error(nil, "Rate limit configured for unknown route", "route", name)
+		}
+	}
+
+	for _, rt := range h.routes {
+		pattern := rt.method + " " + rt.path
+		handler := rt.handler
+		limit, ok := h.config.RateLimit.Routes[pattern]
+		if !ok {
+			limit = h.config.RateLimit.Default
+		}
+		if limit.Requests > 0 {
+			handler = h.rateLimitMiddleware(ratelimit.New(limit.Requests, limit.Per, limit.Burst), handler)
+		}
+		h.mux.HandleFunc(pattern, handler)
+		if _, ok := allowed[rt.path]; !ok {
+			paths = append(paths, rt.path)
+		}
+		allowed[rt.path] = append(allowed[rt.path], rt.method)
+	}
+
+	// Fallbacks are registered per method: a method-less pattern such as
+	// "/products/low-stock" would conflict with "GET /products/{id}".
+	for _, path := range paths {
+		fallback := h.methodNotAllowed(allowHeader(allowed[path]))
+		for _, method := range standardMethods {
+			if slices.Contains(allowed[path], method) {
+				continue
+			}
+			if method == http.MethodHead && slices.Contains(allowed[path], http.MethodGet) {
+				continue
+			}
+			h.mux.HandleFunc(method+" "+path, fallback)
+		}
+	}
+	h.mux.HandleFunc("/", h.notFound)
+}
+
+func (h *Handler) methodNotAllowed(allow string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *Handler) notFound(w http.ResponseWriter, r *http.Request) {
+	h.writeError(w, http.StatusNotFound, "not found")
+}
+
+func allowHeader(methods []string) string {
+	allow := slices.Clone(methods)
+	if slices.Contains(allow, http.MethodGet) && !slices.Contains(allow, http.MethodHead) {
+		allow = append(allow, http.MethodHead)
+	}
+	allow = append(allow, http.MethodOptions)
+	return strings.Join(allow, ", ")
 }
@@ -7,32 +7,55 @@ import (
 	"pet-project/internal/api"
 	"pet-project/internal/config"
 	"pet-project/internal/logger"
+	"pet-project/internal/notify"
+	"pet-project/internal/outbox"
 	"pet-project/internal/service"
 	"pet-project/internal/storage"
+	"pet-project/internal/webhook"
 )
 
 type Application struct {
-	Config  *config.Config
-	Service service.UserService
-	Logger  *logger.Logger
-	Handler *api.Handler
+	Config     *config.Config
+	Service    service.Service
+	Logger     *logger.Logger
+	Handler    *api.Handler
+	Dispatcher *outbox.Dispatcher
+	Deliverer  *webhook.Deliverer
 }
 
-func New(cfg *config.Config, repo *storage.PostgresStorage, logger *logger.Logger) *Application {
-	svc := service.New(repo, logger)
+func New(cfg *config.Config, repo *storage.PostgresStorage, logger *logger.Logger) (*Application, error) {
+	notifier, err := notify.New(cfg.Notifications.LowStock, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create low stock notifier: %w", err)
+	}
+
+	sink, err := outbox.NewSink(cfg.Outbox.Sink, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox sink: %w", err)
+	}
+
+	// Subscriber webhooks are fed from the outbox alongside the configured sink.
+	sink = outbox.MultiSink{sink, webhook.NewFanoutSink(repo)}
+
+	svc := service.New(repo, notifier, logger)
 	handler := api.NewHandler(svc, logger, cfg)
 	return &Application{
-		Config:  cfg,
-		Service: svc,
-		Logger:  logger,
-		Handler: handler,
-	}
+		Config:     cfg,
+		Service:    svc,
+		Logger:     logger,
+		Handler:    handler,
+		Dispatcher: outbox.NewDispatcher(repo, sink, cfg.Outbox, logger),
+		Deliverer:  webhook.NewDeliverer(repo, cfg.Webhooks, logger),
+	}, nil
 }
 
 func (app *Application) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	go app.Dispatcher.Run(ctx)
+	go app.Deliverer.Run(ctx)
+
 	if err := app.Handler.StartServer(ctx); err != nil {
 		app.Logger.Error(err, "Failed to run HTTP server")
 		return fmt.Errorf("failed to run application: %w", err)
@@ -10,9 +10,14 @@ import (
 )
 
 type Config struct {
-	Env        string     `yaml:"env"`
-	HTTPServer HTTPServer `yaml:"http_server"`
-	Database   Database   `yaml:"database"`
+	Env           string        `yaml:"env"`
+	HTTPServer    HTTPServer    `yaml:"http_server"`
+	Database      Database      `yaml:"database"`
+	Notifications Notifications `yaml:"notifications"`
+	Outbox        Outbox        `yaml:"outbox"`
+	Webhooks      Webhooks      `yaml:"webhooks"`
+	RateLimit     RateLimit     `yaml:"rate_limit"`
+	Tax           Tax           `yaml:"tax"`
 }
 
 type HTTPServer struct {
@@ -20,6 +25,11 @@ type HTTPServer struct {
 	Port        int        `yaml:"port"`
 	Timeout     time.Duration `yaml:"timeout"`
 	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// TrustProxy says requests only arrive through a proxy that appends the
+	// client to X-Forwarded-For and sets X-Actor. Without it both headers
+	// come from clients and are ignored.
+	TrustProxy bool `yaml:"trust_proxy"`
 }
 
 type Database struct {
@@ -29,6 +39,84 @@ type Database struct {
 	Password string `yaml:"password"`
 	DBName   string `yaml:"dbname"`
 	MaxConns int    `yaml:"max_conns"`
+
+	// Replicas are connection strings of read-only copies that take reads
+	// off the primary; ReplicaCheckInterval is how often their health is
+	// checked.
+	Replicas             []string      `yaml:"replicas"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval"`
+
+	// Isolation is the default isolation level of units of work, "read
+	// committed" when empty. TxMaxAttempts bounds how often one is run when
+	// it keeps failing on serialization failures or deadlocks.
+	Isolation     string `yaml:"isolation"`
+	TxMaxAttempts int    `yaml:"tx_max_attempts"`
+}
+
+type Notifications struct {
+	LowStock Delivery `yaml:"low_stock"`
+}
+
+type Outbox struct {
+	PollInterval   time.Duration `yaml:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	Sink           Delivery      `yaml:"sink"`
+}
+
+type Webhooks struct {
+	PollInterval   time.Duration `yaml:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size"`
+	Timeout        time.Duration `yaml:"timeout"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+}
+
+// RateLimit limits requests per actor, the identity a trusted proxy states
+// in X-Actor or else the client IP. Routes are keyed by their pattern, e.g.
+// "POST /users"; routes without an entry use Default, and a zero Default
+// leaves them unlimited.
+type RateLimit struct {
+	Default Limit            `yaml:"default"`
+	Routes  map[string]Limit `yaml:"routes"`
+}
+
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+func (l Limit) validate(name string) []error {
+	var errs []error
+	if l.Requests <= 0 {
+		errs = append(errs, fmt.Errorf("rate limit %s requests must be > 0", name))
+	}
+	if l.Per <= 0 {
+		errs = append(errs, fmt.Errorf("rate limit %s period must be > 0", name))
+	}
+	if l.Burst < 0 {
+		errs = append(errs, fmt.Errorf("rate limit %s burst cannot be < 0", name))
+	}
+	return errs
+}
+
+// Tax maps tax class names to rates, e.g. 0.2 for 20%. Rates apply to
+// orders placed after they change; past orders keep the tax they stored.
+type Tax struct {
+	PricesIncludeTax bool               `yaml:"prices_include_tax"`
+	DefaultClass     string             `yaml:"default_class"`
+	Classes          map[string]float64 `yaml:"classes"`
+}
+
+// Delivery selects where notifications or events are sent.
+type Delivery struct {
+	Type    string        `yaml:"type"`
+	Path    string        `yaml:"path"`
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -76,6 +164,20 @@ func LoadConfig(path string) (*Config, error) {
 		errs = append(errs, errors.New("database max connections must be > 0"))
 	}
 
+	if len(cfg.Database.Replicas) > 0 && cfg.Database.ReplicaCheckInterval <= 0 {
+		errs = append(errs, errors.New("database replica check interval must be > 0"))
+	}
+
+	switch cfg.Database.Isolation {
+	case "", "read committed", "repeatable read", "serializable":
+	default:
+		errs = append(errs, fmt.Errorf("unknown database isolation level %q", cfg.Database.Isolation))
+	}
+
+	if cfg.Database.TxMaxAttempts <= 0 {
+		errs = append(errs, errors.New("database tx max attempts must be > 0"))
+	}
+
 	if cfg.HTTPServer.Address == "" {
 		errs = append(errs, errors.New("http server address cannot be empty"))
 	}
@@ -92,6 +194,94 @@ func LoadConfig(path string) (*Config, error) {
 		errs = append(errs, errors.New("http server idle timeout must be > 0"))
 	}
 
+	switch lowStock := cfg.Notifications.LowStock; lowStock.Type {
+	case "", "log":
+	case "file":
+		if lowStock.Path == "" {
+			errs = append(errs, errors.New("low stock file notifier path cannot be empty"))
+		}
+	case "webhook":
+		if lowStock.URL == "" {
+			errs = append(errs, errors.New("low stock webhook url cannot be empty"))
+		}
+		if lowStock.Timeout <= 0 {
+			errs = append(errs, errors.New("low stock webhook timeout must be > 0"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown low stock notifier type %q", lowStock.Type))
+	}
+
+	if cfg.Outbox.PollInterval <= 0 {
+		errs = append(errs, errors.New("outbox poll interval must be > 0"))
+	}
+
+	if cfg.Outbox.BatchSize <= 0 {
+		errs = append(errs, errors.New("outbox batch size must be > 0"))
+	}
+
+	if cfg.Outbox.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("outbox initial backoff must be > 0"))
+	}
+
+	if cfg.Outbox.MaxBackoff < cfg.Outbox.InitialBackoff {
+		errs = append(errs, errors.New("outbox max backoff must be >= initial backoff"))
+	}
+
+	switch sink := cfg.Outbox.Sink; sink.Type {
+	case "", "log":
+	case "webhook":
+		if sink.URL == "" {
+			errs = append(errs, errors.New("outbox webhook url cannot be empty"))
+		}
+		if sink.Timeout <= 0 {
+			errs = append(errs, errors.New("outbox webhook timeout must be > 0"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown outbox sink type %q", sink.Type))
+	}
+
+	if cfg.Webhooks.PollInterval <= 0 {
+		errs = append(errs, errors.New("webhooks poll interval must be > 0"))
+	}
+
+	if cfg.Webhooks.BatchSize <= 0 {
+		errs = append(errs, errors.New("webhooks batch size must be > 0"))
+	}
+
+	if cfg.Webhooks.Timeout <= 0 {
+		errs = append(errs, errors.New("webhooks timeout must be > 0"))
+	}
+
+	if cfg.Webhooks.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("webhooks max attempts must be > 0"))
+	}
+
+	if cfg.Webhooks.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("webhooks initial backoff must be > 0"))
+	}
+
+	if cfg.Webhooks.MaxBackoff < cfg.Webhooks.InitialBackoff {
+		errs = append(errs, errors.New("webhooks max backoff must be >= initial backoff"))
+	}
+
+	if cfg.RateLimit.Default != (Limit{}) {
+		errs = append(errs, cfg.RateLimit.Default.validate("default")...)
+	}
+
+	for route, limit := range cfg.RateLimit.Routes {
+		errs = append(errs, limit.validate(fmt.Sprintf("%q", route))...)
+	}
+
+	if _, ok := cfg.Tax.Classes[cfg.Tax.DefaultClass]; !ok {
+		errs = append(errs, fmt.Errorf("tax default class %q is not one of the tax classes", cfg.Tax.DefaultClass))
+	}
+
+	for class, rate := range cfg.Tax.Classes {
+		if rate < 0 || rate >= 1 {
+			errs = append(errs, fmt.Errorf("tax rate of class %q must be in [0, 1)", class))
+		}
+	}
+
 	if len(errs) > 0 {
 		return nil, fmt.Errorf("validation errors: %v", errs)
 	}
@@ -0,0 +1,17 @@
+package domain
+
+import "errors"
+
+// Errors repositories report for outcomes the service layer turns into
+// responses. Anything else a repository returns is an internal failure.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrVersionConflict = errors.New("version conflict")
+
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrOrderCancelled     = errors.New("order already cancelled")
+	ErrPromoNotApplicable = errors.New("promo code not applicable")
+	ErrPromoExhausted     = errors.New("promo code usage limit reached")
+	ErrUnknownTaxClass    = errors.New("unknown tax class")
+)
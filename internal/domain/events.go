@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AggregateUser    = "user"
+	AggregateProduct = "product"
+	AggregateOrder   = "order"
+)
+
+const (
+	EventUserRegistered = "UserRegistered"
+	EventUserUpdated    = "UserUpdated"
+	EventUserDeleted    = "UserDeleted"
+	EventProductCreated = "ProductCreated"
+	EventProductUpdated = "ProductUpdated"
+	EventOrderCreated   = "OrderCreated"
+	EventOrderCancelled = "OrderCancelled"
+)
+
+// Event is a domain event stored in the outbox until it is published.
+type Event struct {
+	ID            int64
+	AggregateType string
+	AggregateID   int64
+	Type          string
+	Payload       json.RawMessage
+	CreatedAt     time.Time
+	Attempts      int
+}
@@ -0,0 +1,43 @@
+package domain
+
+// File formats of imports and exports.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ProductImportRow is a product read from line Line of an import file. The
+// Has fields say whether the file gave the quantity, tax class and reorder
+// threshold; an upsert keeps those it did not give as they are on the
+// existing product, while a new product gets the defaults.
+type ProductImportRow struct {
+	Line                int
+	Product             Product
+	HasQuantity         bool
+	HasTaxClass         bool
+	HasReorderThreshold bool
+}
+
+// ProductImportOptions control an import. With Upsert every row needs a SKU
+// and rows whose SKU exists update that product; without it an existing SKU
+// is an error. DryRun validates and counts without writing anything.
+type ProductImportOptions struct {
+	DryRun bool
+	Upsert bool
+}
+
+type ImportRowError struct {
+	Line  int
+	SKU   string
+	Error string
+}
+
+// ProductImportResult reports an import. An import is all or nothing: when
+// Errors is not empty nothing was written.
+type ProductImportResult struct {
+	DryRun  bool
+	Rows    int
+	Created int
+	Updated int
+	Errors  []ImportRowError
+}
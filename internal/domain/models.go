@@ -2,35 +2,255 @@ package domain
 
 import "time"
 
+// User is a registered user. Password is only set on a user being created:
+// storage writes it but never reads it back.
 type User struct {
-	ID        int64  `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	FullName  string `json:"full_name"`
-	Age       int    `json:"age"`
-	IsMarried bool   `json:"is_married"`
-	Password  string `json:"password"`
+	ID        int64
+	FirstName string
+	LastName  string
+	FullName  string
+	Age       int
+	IsMarried bool
+	Password  string
+	Version   int64
+}
+
+type UserUpdate struct {
+	FirstName *string
+	LastName  *string
+	IsMarried *bool
+}
+
+type UserFilter struct {
+	Search    string
+	MinAge    *int
+	MaxAge    *int
+	IsMarried *bool
+	SortBy    string
+	SortDesc  bool
+	After     *UserCursor
+	Limit     int
+}
+
+type UserCursor struct {
+	SortBy   string
+	ID       int64
+	FullName string
+	Age      int
+}
+
+type UserPage struct {
+	Users      []User
+	NextCursor string
 }
 
 type Product struct {
-	ID          int64    `json:"id"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags"`
-	Quantity    int      `json:"quantity"`
-	Price       float64  `json:"price"`
+	ID               int64
+	SKU              string
+	Description      string
+	Tags             []string
+	Quantity         int
+	Price            float64
+	TaxClass         string
+	ReorderThreshold *int
+	Version          int64
+}
+
+// ProductVersion is a product as it stood from ValidFrom until its next
+// version. Stock levels are not versioned.
+type ProductVersion struct {
+	ProductID        int64
+	Version          int64
+	Description      string
+	Tags             []string
+	Price            float64
+	TaxClass         string
+	ReorderThreshold *int
+	ValidFrom        time.Time
+}
+
+type ProductVersionList struct {
+	Versions []ProductVersion
+}
+
+type ProductUpdate struct {
+	Description      *string
+	Tags             *[]string
+	Price            *float64
+	TaxClass         *string
+	ReorderThreshold *int
 }
 
+type StockAdjustment struct {
+	Delta  int
+	Reason string
+	Kind   string
+}
+
+const (
+	OrderStatusCreated   = "created"
+	OrderStatusCancelled = "cancelled"
+)
+
 type Order struct {
-	ID           int            `json:"id"`
-	UserID       int            `json:"user_id"`
-	CreatedAt    time.Time      `json:"created_at"`
-	TotalPrice   float64        `json:"total_price"`
-	OrderProduct []OrderProduct `json:"order_products"`
+	ID           int64
+	UserID       int64
+	Status       string
+	CreatedAt    time.Time
+	CancelledAt  *time.Time
+	Subtotal     float64
+	Discount     float64
+	PromoCode    string
+	NetTotal     float64
+	TaxTotal     float64
+	TotalPrice   float64
+	OrderProduct []OrderProduct
 }
 
+// OrderProduct is an order line. ProductVersion is the version that was
+// ordered, and Description, Tags and Price are copied from it; Discount is
+// the line's share of the order discount and TaxAmount the tax charged on
+// the discounted line.
 type OrderProduct struct {
-	OrderID   int     `json:"order_id"`
-	ProductID int     `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
+	OrderID        int64
+	ProductID      int64
+	ProductVersion int64
+	Description    string
+	Tags           []string
+	Quantity       int
+	Price          float64
+	Discount       float64
+	TaxClass       string
+	TaxRate        float64
+	TaxAmount      float64
+}
+
+// OrderExportRow is an order line flattened together with its order.
+type OrderExportRow struct {
+	OrderID        int64
+	UserID         int64
+	CreatedAt      time.Time
+	Status         string
+	ProductID      int64
+	ProductVersion int64
+	Description    string
+	Quantity       int
+	Price          float64
+	Discount       float64
+	TaxAmount      float64
+	OrderTotal     float64
+}
+
+type NewOrder struct {
+	UserID    int64
+	Items     []OrderItem
+	PromoCode string
+}
+
+type OrderItem struct {
+	ProductID int64
+	Quantity  int
+}
+
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// PromoCode discounts the lines it applies to: those of ProductIDs or
+// carrying one of Tags, or every line when both are empty. Codes are
+// matched case-insensitively.
+type PromoCode struct {
+	ID             int64
+	Code           string
+	Kind           string
+	Value          float64
+	MinOrderValue  float64
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	MaxUses        *int
+	MaxUsesPerUser *int
+	ProductIDs     []int64
+	Tags           []string
+	CreatedAt      time.Time
+}
+
+const (
+	MovementInitial      = "initial"
+	MovementOrder        = "order"
+	MovementCancellation = "cancellation"
+	MovementRestock      = "restock"
+	MovementCorrection   = "correction"
+)
+
+type StockMovement struct {
+	ID            int64
+	ProductID     int64
+	Kind          string
+	Delta         int
+	QuantityAfter int
+	Reason        string
+	OrderID       *int64
+	CreatedAt     time.Time
+}
+
+// LowStockAlert says an order or an import took a product's stock to its
+// reorder threshold. OrderID is zero for an import.
+type LowStockAlert struct {
+	ProductID        int64
+	Description      string
+	Quantity         int
+	ReorderThreshold int
+	OrderID          int64
+	OccurredAt       time.Time
+}
+
+type StockMovementPage struct {
+	Movements  []StockMovement
+	NextCursor string
+}
+
+type StockDiscrepancy struct {
+	ProductID   int64
+	Quantity    int
+	LedgerTotal int
+}
+
+type StockReconciliation struct {
+	Consistent    bool
+	Discrepancies []StockDiscrepancy
+}
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one change. Before and After hold only the fields
+// that changed; Before is empty for a create.
+type AuditEntry struct {
+	ID        int64
+	Actor     string
+	RequestID string
+	Entity    string
+	EntityID  int64
+	Action    string
+	Before    map[string]any
+	After     map[string]any
+	CreatedAt time.Time
+}
+
+type AuditFilter struct {
+	Entity   string
+	EntityID *int64
+	From     *time.Time
+	To       *time.Time
+	Before   int64
+	Limit    int
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry
+	NextCursor string
 }
@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+const (
+	ReportPeriodDay   = "day"
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+var ReportPeriods = []string{ReportPeriodDay, ReportPeriodWeek, ReportPeriodMonth}
+
+// ReportFilter selects the orders created in [From, To). Period and
+// TimeZone only apply to revenue, Limit only to the top lists.
+type ReportFilter struct {
+	From     time.Time
+	To       time.Time
+	Period   string
+	TimeZone string
+	Limit    int
+}
+
+// RevenueRow covers the period starting at PeriodStart, a date in the
+// report's time zone; weeks start on Monday. Like every report it uses the
+// prices stored on the order lines and leaves out cancelled orders.
+// Revenue is after discount, and includes tax only if prices do.
+type RevenueRow struct {
+	PeriodStart string
+	Orders      int
+	Items       int
+	GrossSales  float64
+	Discount    float64
+	Revenue     float64
+	Tax         float64
+}
+
+type RevenueReport struct {
+	From     time.Time
+	To       time.Time
+	Period   string
+	TimeZone string
+	Rows     []RevenueRow
+}
+
+// ProductSales describes a product by the description on its latest order
+// line in the range.
+type ProductSales struct {
+	ProductID   int64
+	Description string
+	Quantity    int
+	Orders      int
+	Revenue     float64
+}
+
+type TopProductsReport struct {
+	From     time.Time
+	To       time.Time
+	Products []ProductSales
+}
+
+type CustomerSales struct {
+	UserID    int64
+	FirstName string
+	LastName  string
+	Orders    int
+	Items     int
+	Revenue   float64
+}
+
+type TopCustomersReport struct {
+	From      time.Time
+	To        time.Time
+	Customers []CustomerSales
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+// EventTypes lists every event a webhook can subscribe to.
+var EventTypes = []string{
+	EventUserRegistered,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventProductCreated,
+	EventProductUpdated,
+	EventOrderCreated,
+	EventOrderCancelled,
+}
+
+type WebhookSubscription struct {
+	ID         int64
+	URL        string
+	EventTypes []string
+	Secret     string
+	CreatedAt  time.Time
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int
+	ResponseCode   *int
+	LastError      *string
+	NextAttemptAt  time.Time
+	LastAttemptAt  *time.Time
+	CreatedAt      time.Time
+	Log            []WebhookAttempt
+	Request        *WebhookRequest
+}
+
+// WebhookRequest is what the deliverer needs to send a delivery.
+type WebhookRequest struct {
+	URL    string
+	Secret string
+	Event  Event
+}
+
+type WebhookAttempt struct {
+	AttemptedAt  time.Time
+	ResponseCode *int
+	Error        string
+	DurationMS   int
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery
+	NextCursor string
+}
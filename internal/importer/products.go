@@ -0,0 +1,260 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+	"pet-project/internal/validation"
+)
+
+// CSVColumns are the columns a CSV import may have, in any order. Only
+// description and price are required; tags are separated by "|". An upsert
+// leaves the quantity, tax class and reorder threshold of existing products
+// alone when their columns are missing.
+var CSVColumns = []string{"sku", "description", "tags", "quantity", "price", "tax_class", "reorder_threshold"}
+
+const maxNDJSONLine = 1 << 20
+
+// ReadProducts parses and validates an import file. Problems with single
+// rows are collected in the returned report, which also counts the rows
+// read; an error is only returned when the file as a whole is unreadable.
+func ReadProducts(r io.Reader, format string, opts domain.ProductImportOptions) ([]domain.ProductImportRow, domain.ProductImportResult, error) {
+	var rows []domain.ProductImportRow
+	report := domain.ProductImportResult{DryRun: opts.DryRun}
+	seen := make(map[string]int)
+
+	add := func(row domain.ProductImportRow, err error) {
+		line, product := row.Line, row.Product
+		report.Rows++
+		if err == nil {
+			err = validation.ValidateCreateProduct(product)
+		}
+		if err == nil && opts.Upsert && product.SKU == "" {
+			err = errors.New("sku is required to upsert")
+		}
+		if first, ok := seen[product.SKU]; err == nil && ok {
+			err = fmt.Errorf("sku is already used on line %d", first)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, domain.ImportRowError{Line: line, SKU: product.SKU, Error: message(err)})
+			return
+		}
+		if product.SKU != "" {
+			seen[product.SKU] = line
+		}
+		rows = append(rows, row)
+	}
+
+	var err error
+	switch format {
+	case domain.FormatCSV:
+		err = readCSV(r, add)
+	case domain.FormatNDJSON:
+		err = readNDJSON(r, add)
+	default:
+		err = fmt.Errorf("unsupported import format %q, want %s or %s", format, domain.FormatCSV, domain.FormatNDJSON)
+	}
+	if err != nil {
+		return nil, domain.ProductImportResult{}, err
+	}
+	if report.Rows == 0 {
+		return nil, domain.ProductImportResult{}, errors.New("import file has no rows")
+	}
+	return rows, report, nil
+}
+
+func readCSV(r io.Reader, add func(domain.ProductImportRow, error)) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("import file is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(CSVColumns, name) {
+			return fmt.Errorf("unknown csv column %q, want some of %s", name, strings.Join(CSVColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return fmt.Errorf("csv column %q appears more than once", name)
+		}
+		columns[name] = i
+	}
+	has := func(name string) bool {
+		_, ok := columns[name]
+		return ok
+	}
+	for _, name := range []string{"description", "price"} {
+		if !has(name) {
+			return fmt.Errorf("csv column %q is required", name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			add(domain.ProductImportRow{Line: parseErr.Line}, fmt.Errorf("row has %d fields, header has %d", len(record), len(header)))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		product, err := parseCSVProduct(field)
+		add(domain.ProductImportRow{
+			Line:                line,
+			Product:             product,
+			HasQuantity:         has("quantity"),
+			HasTaxClass:         has("tax_class"),
+			HasReorderThreshold: has("reorder_threshold"),
+		}, err)
+	}
+}
+
+func parseCSVProduct(field func(string) string) (domain.Product, error) {
+	product := domain.Product{
+		SKU:         field("sku"),
+		Description: field("description"),
+		TaxClass:    field("tax_class"),
+	}
+	for _, tag := range strings.Split(field("tags"), "|") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			product.Tags = append(product.Tags, tag)
+		}
+	}
+
+	var err error
+	if value := field("quantity"); value != "" {
+		if product.Quantity, err = strconv.Atoi(value); err != nil {
+			return product, fmt.Errorf("quantity %q is not an integer", value)
+		}
+	}
+	if value := field("price"); value != "" {
+		if product.Price, err = strconv.ParseFloat(value, 64); err != nil {
+			return product, fmt.Errorf("price %q is not a number", value)
+		}
+	} else {
+		return product, errors.New("price is required")
+	}
+	if value := field("reorder_threshold"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil {
+			return product, fmt.Errorf("reorder_threshold %q is not an integer", value)
+		}
+		product.ReorderThreshold = &threshold
+	}
+	return product, nil
+}
+
+// productRecord is one NDJSON line. It is separate from domain.Product so
+// that fields an import cannot set, like id, are rejected. The reorder
+// threshold is kept raw to tell a missing one from null, which clears it.
+type productRecord struct {
+	SKU              string          `json:"sku"`
+	Description      string          `json:"description"`
+	Tags             []string        `json:"tags"`
+	Quantity         *int            `json:"quantity"`
+	Price            *float64        `json:"price"`
+	TaxClass         *string         `json:"tax_class"`
+	ReorderThreshold json.RawMessage `json:"reorder_threshold"`
+}
+
+func readNDJSON(r io.Reader, add func(domain.ProductImportRow, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxNDJSONLine)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var record productRecord
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			add(domain.ProductImportRow{Line: line}, fmt.Errorf("invalid json: %w", err))
+			continue
+		}
+		if decoder.More() {
+			add(domain.ProductImportRow{Line: line, Product: domain.Product{SKU: record.SKU}}, errors.New("line holds more than one json value"))
+			continue
+		}
+
+		row := domain.ProductImportRow{
+			Line: line,
+			Product: domain.Product{
+				SKU:         strings.TrimSpace(record.SKU),
+				Description: record.Description,
+				Tags:        record.Tags,
+			},
+			HasQuantity:         record.Quantity != nil,
+			HasTaxClass:         record.TaxClass != nil,
+			HasReorderThreshold: record.ReorderThreshold != nil,
+		}
+		if record.Quantity != nil {
+			row.Product.Quantity = *record.Quantity
+		}
+		if record.TaxClass != nil {
+			row.Product.TaxClass = *record.TaxClass
+		}
+		if row.HasReorderThreshold {
+			if err := json.Unmarshal(record.ReorderThreshold, &row.Product.ReorderThreshold); err != nil {
+				add(row, fmt.Errorf("reorder_threshold %s is not an integer", record.ReorderThreshold))
+				continue
+			}
+		}
+		if record.Price == nil {
+			add(row, errors.New("price is required"))
+			continue
+		}
+		row.Product.Price = *record.Price
+		add(row, nil)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read ndjson: %w", err)
+	}
+	return nil
+}
+
+// message drops the ErrValidation marker validators join to their errors,
+// which says nothing in a report made only of validation errors.
+func message(err error) string {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err.Error()
+	}
+	var parts []string
+	for _, err := range joined.Unwrap() {
+		if err != service.ErrValidation {
+			parts = append(parts, err.Error())
+		}
+	}
+	return strings.Join(parts, ": ")
+}
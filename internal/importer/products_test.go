@@ -0,0 +1,61 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"pet-project/internal/domain"
+)
+
+func TestReadProductsTellsMissingFields(t *testing.T) {
+	tests := []struct {
+		name                                   string
+		format, input                          string
+		hasQuantity, hasTaxClass, hasThreshold bool
+		wantQuantity                           int
+		wantThreshold                          *int
+	}{
+		{"csv without optional columns", domain.FormatCSV, "sku,description,price\na-1,Pen,1.5\n", false, false, false, 0, nil},
+		{"csv with them", domain.FormatCSV, "sku,description,price,quantity,tax_class,reorder_threshold\na-1,Pen,1.5,7,standard,3\n", true, true, true, 7, ptr(3)},
+		{"csv with an empty threshold", domain.FormatCSV, "sku,description,price,reorder_threshold\na-1,Pen,1.5,\n", false, false, true, 0, nil},
+		{"ndjson without optional fields", domain.FormatNDJSON, `{"sku":"a-1","description":"Pen","price":1.5}`, false, false, false, 0, nil},
+		{"ndjson with them", domain.FormatNDJSON, `{"sku":"a-1","description":"Pen","price":1.5,"quantity":0,"tax_class":"standard","reorder_threshold":3}`, true, true, true, 0, ptr(3)},
+		{"ndjson with a null threshold", domain.FormatNDJSON, `{"sku":"a-1","description":"Pen","price":1.5,"reorder_threshold":null}`, false, false, true, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, report, err := ReadProducts(strings.NewReader(tt.input), tt.format, domain.ProductImportOptions{Upsert: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Errors) > 0 || len(rows) != 1 {
+				t.Fatalf("rows = %+v, errors = %+v; want one row", rows, report.Errors)
+			}
+			row := rows[0]
+			if row.HasQuantity != tt.hasQuantity || row.HasTaxClass != tt.hasTaxClass || row.HasReorderThreshold != tt.hasThreshold {
+				t.Errorf("has quantity %v, tax class %v, threshold %v; want %v, %v, %v",
+					row.HasQuantity, row.HasTaxClass, row.HasReorderThreshold, tt.hasQuantity, tt.hasTaxClass, tt.hasThreshold)
+			}
+			if row.Product.Quantity != tt.wantQuantity {
+				t.Errorf("quantity = %d, want %d", row.Product.Quantity, tt.wantQuantity)
+			}
+			if got := row.Product.ReorderThreshold; (got == nil) != (tt.wantThreshold == nil) || got != nil && *got != *tt.wantThreshold {
+				t.Errorf("reorder threshold = %v, want %v", got, tt.wantThreshold)
+			}
+		})
+	}
+}
+
+func TestReadProductsRejectsBadThreshold(t *testing.T) {
+	_, report, err := ReadProducts(strings.NewReader(`{"description":"Pen","price":1.5,"reorder_threshold":"low"}`), domain.FormatNDJSON, domain.ProductImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Error != `reorder_threshold "low" is not an integer` {
+		t.Errorf("errors = %+v, want the threshold rejected", report.Errors)
+	}
+}
+
+func ptr(n int) *int {
+	return &n
+}
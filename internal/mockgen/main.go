@@ -0,0 +1,190 @@
+// Command mockgen writes a mock for every interface declared in a Go source
+// file. A mock has a <Method>Func field per method that the test sets to
+// the behaviour it wants; calling a method whose field is nil panics, so
+// unexpected calls fail the test. Interfaces made only of other interfaces
+// become structs embedding their mocks.
+//
+//	go run pet-project/internal/mockgen -src ports.go -out mocks/ports.go -pkg mocks
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	src := flag.String("src", "", "Go file declaring the interfaces")
+	out := flag.String("out", "", "file to write the mocks to")
+	pkg := flag.String("pkg", "mocks", "package name of the mocks")
+	flag.Parse()
+	if *src == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	code, err := generate(*src, *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(src, pkg string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, src, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", src, err)
+	}
+
+	var body bytes.Buffer
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+			if iface, ok := spec.Type.(*ast.InterfaceType); ok && spec.Name.IsExported() {
+				if err := writeMock(&body, fset, spec.Name.Name, iface); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "// Code generated by mockgen from %s; DO NOT EDIT.\n\n", filepath.Base(src))
+	fmt.Fprintf(&code, "package %s\n\n", pkg)
+	// Standard library imports first, then the rest, as goimports groups them.
+	var std, other []string
+	for _, imp := range file.Imports {
+		if !usesImport(body.String(), imp) {
+			continue
+		}
+		if pkg, err := build.Import(strings.Trim(imp.Path.Value, `"`), "", build.FindOnly); err == nil && pkg.Goroot {
+			std = append(std, node(fset, imp))
+		} else {
+			other = append(other, node(fset, imp))
+		}
+	}
+	code.WriteString("import (\n")
+	for _, group := range [][]string{std, other} {
+		for _, imp := range group {
+			fmt.Fprintf(&code, "\t%s\n", imp)
+		}
+		if len(group) > 0 {
+			code.WriteString("\n")
+		}
+	}
+	code.WriteString(")\n")
+	code.Write(body.Bytes())
+
+	formatted, err := format.Source(code.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format mocks: %w", err)
+	}
+	return formatted, nil
+}
+
+func writeMock(w *bytes.Buffer, fset *token.FileSet, name string, iface *ast.InterfaceType) error {
+	var fields, methods bytes.Buffer
+	for _, m := range iface.Methods.List {
+		fn, ok := m.Type.(*ast.FuncType)
+		if !ok {
+			embedded, ok := m.Type.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("%s embeds %s, only interfaces of the same file are supported", name, node(fset, m.Type))
+			}
+			fmt.Fprintf(&fields, "\t%s\n", embedded.Name)
+			continue
+		}
+
+		method := m.Names[0].Name
+		params, args := parameters(fset, fn.Params)
+		results, call := resultList(fset, fn.Results), ""
+		if results != "" {
+			call = "return "
+		}
+
+		fmt.Fprintf(&fields, "\t%sFunc func(%s) %s\n", method, params, results)
+		fmt.Fprintf(&methods, "\nfunc (m *%s) %s(%s) %s {\n", name, method, params, results)
+		fmt.Fprintf(&methods, "\tif m.%sFunc == nil {\n\t\tpanic(\"unexpected call to %s.%s\")\n\t}\n", method, name, method)
+		fmt.Fprintf(&methods, "\t%sm.%sFunc(%s)\n}\n", call, method, args)
+	}
+
+	fmt.Fprintf(w, "\ntype %s struct {\n%s}\n%s", name, fields.String(), methods.String())
+	return nil
+}
+
+// parameters returns the parameter list with every parameter named, and
+// the arguments that pass them on.
+func parameters(fset *token.FileSet, list *ast.FieldList) (string, string) {
+	var params, args []string
+	for i, field := range list.List {
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("p%d", i))}
+		}
+		typ := node(fset, field.Type)
+		for _, n := range names {
+			params = append(params, n.Name+" "+typ)
+			arg := n.Name
+			if _, ok := field.Type.(*ast.Ellipsis); ok {
+				arg += "..."
+			}
+			args = append(args, arg)
+		}
+	}
+	return strings.Join(params, ", "), strings.Join(args, ", ")
+}
+
+func resultList(fset *token.FileSet, list *ast.FieldList) string {
+	if list == nil {
+		return ""
+	}
+	var results []string
+	for _, field := range list.List {
+		typ := node(fset, field.Type)
+		if len(field.Names) == 0 {
+			results = append(results, typ)
+		}
+		for _, n := range field.Names {
+			results = append(results, n.Name+" "+typ)
+		}
+	}
+	if len(results) == 1 && len(list.List[0].Names) == 0 {
+		return results[0]
+	}
+	return "(" + strings.Join(results, ", ") + ")"
+}
+
+func usesImport(body string, imp *ast.ImportSpec) bool {
+	name := strings.Trim(imp.Path.Value, `"`)
+	name = name[strings.LastIndex(name, "/")+1:]
+	if imp.Name != nil {
+		name = imp.Name.Name
+	}
+	return strings.Contains(body, name+".")
+}
+
+func node(fset *token.FileSet, n any) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, n)
+	return b.String()
+}
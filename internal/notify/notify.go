@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+)
+
+type Notifier interface {
+	NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error
+}
+
+func New(cfg config.Delivery, logger *logger.Logger) (Notifier, error) {
+	switch cfg.Type {
+	case "", "log":
+		return NewLogNotifier(logger), nil
+	case "file":
+		return NewFileNotifier(cfg.Path), nil
+	case "webhook":
+		return NewWebhookNotifier(cfg.URL, &http.Client{Timeout: cfg.Timeout}), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", cfg.Type)
+	}
+}
+
+type LogNotifier struct {
+	logger *logger.Logger
+}
+
+func NewLogNotifier(logger *logger.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	n.logger.Info("Low stock",
+		"product_id", alert.ProductID,
+		"description", alert.Description,
+		"quantity", alert.Quantity,
+		"reorder_threshold", alert.ReorderThreshold,
+		"order_id", alert.OrderID,
+	)
+	return nil
+}
+
+// alertMessage is how FileNotifier and WebhookNotifier write an alert.
+type alertMessage struct {
+	ProductID        int64     `json:"product_id"`
+	Description      string    `json:"description"`
+	Quantity         int       `json:"quantity"`
+	ReorderThreshold int       `json:"reorder_threshold"`
+	OrderID          int64     `json:"order_id,omitempty"`
+	OccurredAt       time.Time `json:"occurred_at"`
+}
+
+func encodeAlert(alert domain.LowStockAlert) ([]byte, error) {
+	data, err := json.Marshal(alertMessage{
+		ProductID:        alert.ProductID,
+		Description:      alert.Description,
+		Quantity:         alert.Quantity,
+		ReorderThreshold: alert.ReorderThreshold,
+		OrderID:          alert.OrderID,
+		OccurredAt:       alert.OccurredAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alert: %w", err)
+	}
+	return data, nil
+}
+
+// FileNotifier appends one JSON object per alert to a file.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	line, err := encodeAlert(alert)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", n.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", n.path, err)
+	}
+	return nil
+}
+
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: client}
+}
+
+func (n *WebhookNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	body, err := encodeAlert(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
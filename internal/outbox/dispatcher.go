@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+)
+
+type Store interface {
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]domain.Event, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	RetryOutboxEvent(ctx context.Context, id int64, retryIn time.Duration, reason string) error
+}
+
+type Dispatcher struct {
+	store  Store
+	sink   Sink
+	cfg    config.Outbox
+	logger *logger.Logger
+}
+
+func NewDispatcher(store Store, sink Sink, cfg config.Outbox, logger *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		sink:   sink,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run publishes pending events until ctx is cancelled. A full batch is
+// followed immediately by the next one so a backlog drains quickly.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.logger.Info("Starting outbox dispatcher", "poll_interval", d.cfg.PollInterval, "batch_size", d.cfg.BatchSize)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Outbox dispatcher stopped")
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := d.dispatchBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Error(err, "Failed to process outbox")
+		}
+
+		next := d.cfg.PollInterval
+		if err == nil && claimed == d.cfg.BatchSize {
+			next = 0
+		}
+		timer.Reset(next)
+	}
+}
+
+// dispatchBatch publishes a batch concurrently. A batch holds at most one
+// event of every aggregate, so this does not reorder any aggregate's events.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	// The lease outlives the sink timeout so an event being published is
+	// not claimed a second time.
+	events, err := d.store.ClaimOutboxEvents(ctx, d.cfg.BatchSize, d.cfg.Sink.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, event := range events {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.publish(ctx, event)
+		}()
+	}
+	wg.Wait()
+	return len(events), nil
+}
+
+func (d *Dispatcher) publish(ctx context.Context, event domain.Event) {
+	if err := d.sink.Publish(ctx, event); err != nil {
+		attempts := event.Attempts + 1
+		retryIn := Backoff(d.cfg.InitialBackoff, d.cfg.MaxBackoff, attempts)
+		d.logger.Error(err, "Failed to publish outbox event", "id", event.ID, "type", event.Type, "attempts", attempts, "retry_in", retryIn)
+		if err := d.store.RetryOutboxEvent(ctx, event.ID, retryIn, err.Error()); err != nil && ctx.Err() == nil {
+			d.logger.Error(err, "Failed to reschedule outbox event", "id", event.ID)
+		}
+		return
+	}
+
+	if err := d.store.MarkOutboxEventPublished(ctx, event.ID); err != nil && ctx.Err() == nil {
+		d.logger.Error(err, "Failed to mark outbox event published", "id", event.ID)
+		return
+	}
+	d.logger.Debug("Outbox event published", "id", event.ID, "type", event.Type)
+}
+
+// Backoff doubles initial with every failed attempt up to ceiling and adds
+// up to 20% jitter so retries of many events do not arrive in lockstep.
+func Backoff(initial, ceiling time.Duration, attempts int) time.Duration {
+	delay := initial
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+	delay = min(delay, ceiling)
+	return delay + rand.N(delay/5+1)
+}
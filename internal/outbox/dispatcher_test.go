@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+)
+
+type retry struct {
+	retryIn time.Duration
+	reason  string
+}
+
+// fakeStore hands out the events it holds as one batch and records what the
+// dispatcher made of them. Which events are eligible is up to the storage
+// and covered by its conformance suite.
+type fakeStore struct {
+	mu        sync.Mutex
+	events    []domain.Event
+	lease     time.Duration
+	published []int64
+	retries   map[int64]retry
+}
+
+func (s *fakeStore) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lease = lease
+	claimed := s.events[:min(limit, len(s.events))]
+	s.events = s.events[len(claimed):]
+	return claimed, nil
+}
+
+func (s *fakeStore) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published = append(s.published, id)
+	return nil
+}
+
+func (s *fakeStore) RetryOutboxEvent(ctx context.Context, id int64, retryIn time.Duration, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries[id] = retry{retryIn, reason}
+	return nil
+}
+
+// failingSink fails to publish the events in fail.
+type failingSink struct {
+	fail map[int64]bool
+}
+
+func (s failingSink) Publish(ctx context.Context, event domain.Event) error {
+	if s.fail[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	return nil
+}
+
+func TestDispatchBatch(t *testing.T) {
+	store := &fakeStore{
+		events: []domain.Event{
+			{ID: 1, AggregateType: domain.AggregateOrder, AggregateID: 5},
+			{ID: 2, AggregateType: domain.AggregateOrder, AggregateID: 6, Attempts: 2},
+			{ID: 3, AggregateType: domain.AggregateOrder, AggregateID: 7},
+			{ID: 4, AggregateType: domain.AggregateOrder, AggregateID: 8},
+		},
+		retries: make(map[int64]retry),
+	}
+	cfg := config.Outbox{
+		BatchSize:      3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Sink:           config.Delivery{Timeout: 5 * time.Second},
+	}
+	d := NewDispatcher(store, failingSink{fail: map[int64]bool{2: true}}, cfg, logger.New("test"))
+
+	claimed, err := d.dispatchBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed != 3 {
+		t.Errorf("claimed %d events, want a batch of 3", claimed)
+	}
+	if store.lease <= cfg.Sink.Timeout {
+		t.Errorf("lease %s does not outlive the sink timeout %s", store.lease, cfg.Sink.Timeout)
+	}
+
+	slices.Sort(store.published)
+	if !slices.Equal(store.published, []int64{1, 3}) {
+		t.Errorf("published %v, want [1 3]", store.published)
+	}
+	// Event 2 failed its third attempt, so it waits four times the initial
+	// backoff plus up to 20% jitter.
+	r, ok := store.retries[2]
+	if !ok || r.retryIn < 4*time.Second || r.retryIn > 4800*time.Millisecond || r.reason != "sink unavailable" {
+		t.Errorf("retry of event 2 = %+v, %v; want in 4s to 4.8s for sink unavailable", r, ok)
+	}
+	if len(store.retries) != 1 {
+		t.Errorf("retries = %v, want only event 2", store.retries)
+	}
+}
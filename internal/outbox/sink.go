@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+)
+
+// Sink publishes an event to downstream systems. Delivery is at least once,
+// so consumers must tolerate duplicates, e.g. by remembering event IDs.
+type Sink interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+func NewSink(cfg config.Delivery, logger *logger.Logger) (Sink, error) {
+	switch cfg.Type {
+	case "", "log":
+		return NewLogSink(logger), nil
+	case "webhook":
+		return NewWebhookSink(cfg.URL, &http.Client{Timeout: cfg.Timeout}), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink type %q", cfg.Type)
+	}
+}
+
+// MultiSink publishes every event to all of its sinks. An event is retried
+// when any of them fails, so each must tolerate seeing it again.
+type MultiSink []Sink
+
+func (m MultiSink) Publish(ctx context.Context, event domain.Event) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type LogSink struct {
+	logger *logger.Logger
+}
+
+func NewLogSink(logger *logger.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Publish(ctx context.Context, event domain.Event) error {
+	s.logger.Info("Event published",
+		"event_id", event.ID,
+		"type", event.Type,
+		"aggregate_type", event.AggregateType,
+		"aggregate_id", event.AggregateID,
+		"payload", string(event.Payload),
+	)
+	return nil
+}
+
+// message is the body WebhookSink POSTs for an event.
+type message struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	return &WebhookSink{url: url, client: client}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event domain.Event) error {
+	body, err := json.Marshal(message{
+		ID:            event.ID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Type:          event.Type,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("event-%d", event.ID))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
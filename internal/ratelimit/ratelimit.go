@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a request against a limiter. Durations are
+// rounded up to whole seconds for the HTTP headers that carry them.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a set of token buckets, one per key. Every bucket holds up to
+// burst tokens and refills at rate tokens per second.
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New allows requests per period with bursts of up to burst requests. A
+// burst of zero defaults to requests.
+func New(requests int, per time.Duration, burst int) *Limiter {
+	if burst <= 0 {
+		burst = requests
+	}
+	return &Limiter{
+		rate:    float64(requests) / per.Seconds(),
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *Limiter) Allow(key string, now time.Time) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	d := Decision{Limit: int(l.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+	d.Remaining = int(math.Floor(b.tokens))
+	d.Reset = l.duration(l.burst - b.tokens)
+	return d
+}
+
+// sweep forgets buckets that have refilled completely, since a new bucket
+// for the same key would be identical. It runs at most once per refill
+// period so Allow stays cheap.
+func (l *Limiter) sweep(now time.Time) {
+	refill := l.duration(l.burst)
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
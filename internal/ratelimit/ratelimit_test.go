@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestAllow(t *testing.T) {
+	// One token a second, up to three at once.
+	l := New(1, time.Second, 3)
+
+	steps := []struct {
+		name    string
+		elapsed time.Duration
+		want    Decision
+	}{
+		{"burst", 0, Decision{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+		{"burst", 0, Decision{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second}},
+		{"burst", 0, Decision{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+		{"empty", 0, Decision{Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}},
+		{"refilled", 1500 * time.Millisecond, Decision{Allowed: true, Limit: 3, Remaining: 0, Reset: 2500 * time.Millisecond}},
+		{"partly refilled", 1500 * time.Millisecond, Decision{Limit: 3, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{"capped at burst", time.Hour, Decision{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+	}
+	for i, step := range steps {
+		if got := l.Allow("client", start.Add(step.elapsed)); got != step.want {
+			t.Errorf("step %d (%s): Allow = %+v, want %+v", i+1, step.name, got, step.want)
+		}
+	}
+
+	if got := l.Allow("other", start); !got.Allowed || got.Remaining != 2 {
+		t.Errorf("other key: Allow = %+v, want its own full bucket", got)
+	}
+}
+
+func TestBurstDefaultsToRequests(t *testing.T) {
+	l := New(2, time.Minute, 0)
+	for i := range 2 {
+		if d := l.Allow("client", start); !d.Allowed || d.Limit != 2 {
+			t.Fatalf("request %d: Allow = %+v, want allowed with limit 2", i+1, d)
+		}
+	}
+	if d := l.Allow("client", start); d.Allowed || d.RetryAfter != 30*time.Second {
+		t.Errorf("request 3: Allow = %+v, want refused for 30s", d)
+	}
+}
+
+func TestSweepForgetsIdleKeys(t *testing.T) {
+	// Buckets refill completely in three seconds.
+	l := New(1, time.Second, 3)
+
+	l.Allow("idle", start)
+	l.Allow("recent", start.Add(2*time.Second))
+	if got := l.keys(); !slices.Equal(got, []string{"idle", "recent"}) {
+		t.Fatalf("keys before a refill period passed = %v; sweeping should wait", got)
+	}
+
+	l.Allow("new", start.Add(3*time.Second))
+	if got := l.keys(); !slices.Equal(got, []string{"new", "recent"}) {
+		t.Errorf("keys after the sweep = %v, want [new recent]", got)
+	}
+
+	// A swept key starts over with a full bucket.
+	if d := l.Allow("idle", start.Add(3*time.Second)); !d.Allowed || d.Remaining != 2 {
+		t.Errorf("swept key: Allow = %+v, want a full bucket", d)
+	}
+}
+
+func (l *Limiter) keys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var keys []string
+	for key := range l.buckets {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
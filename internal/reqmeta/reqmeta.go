@@ -0,0 +1,39 @@
+// Package reqmeta carries facts about the request that caused a change,
+// such as who made it, down to the layers that record it.
+package reqmeta
+
+import "context"
+
+// SystemActor is the actor of changes made outside any request.
+const SystemActor = "system"
+
+type Meta struct {
+	RequestID string
+	Actor     string
+
+	// ReadPrimary sends the request's reads to the primary database rather
+	// than to a replica, which may not have applied the latest writes yet.
+	ReadPrimary bool
+}
+
+type contextKey struct{}
+
+func With(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, contextKey{}, meta)
+}
+
+func From(ctx context.Context) Meta {
+	meta, _ := ctx.Value(contextKey{}).(Meta)
+	if meta.Actor == "" {
+		meta.Actor = SystemActor
+	}
+	return meta
+}
+
+// WithReadPrimary marks ctx so reads done with it see every committed
+// write, e.g. to read back what the caller has just written.
+func WithReadPrimary(ctx context.Context) context.Context {
+	meta, _ := ctx.Value(contextKey{}).(Meta)
+	meta.ReadPrimary = true
+	return With(ctx, meta)
+}
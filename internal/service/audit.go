@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"pet-project/internal/domain"
+)
+
+type AuditService interface {
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter, cursor string) (domain.AuditPage, error)
+}
+
+func (s *service) ListAuditEntries(ctx context.Context, filter domain.AuditFilter, cursor string) (domain.AuditPage, error) {
+	s.logger.Debug("Listing audit entries", "entity", filter.Entity)
+	if cursor != "" {
+		before, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return domain.AuditPage{}, fmt.Errorf("%w: invalid cursor", ErrValidation)
+		}
+		filter.Before = before
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.repo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		s.logger.Error(err, "Failed to list audit entries")
+		return domain.AuditPage{}, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	page := domain.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = strconv.FormatInt(page.Entries[limit-1].ID, 10)
+	}
+	return page, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pet-project/internal/domain"
+)
+
+// userCursor is the encoded form of domain.UserCursor, kept short since it
+// travels in URLs.
+type userCursor struct {
+	SortBy   string `json:"s"`
+	ID       int64  `json:"id"`
+	FullName string `json:"n,omitempty"`
+	Age      int    `json:"a,omitempty"`
+}
+
+func encodeUserCursor(sortBy string, user domain.User) string {
+	data, _ := json.Marshal(userCursor{
+		SortBy:   sortBy,
+		ID:       user.ID,
+		FullName: user.FullName,
+		Age:      user.Age,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(cursor, sortBy string) (*domain.UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+
+	var c userCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	if c.SortBy != sortBy {
+		return nil, errors.Join(ErrValidation, fmt.Errorf("cursor was issued for sort %q", c.SortBy))
+	}
+	return &domain.UserCursor{SortBy: c.SortBy, ID: c.ID, FullName: c.FullName, Age: c.Age}, nil
+}
@@ -0,0 +1,325 @@
+// Code generated by mockgen from ports.go; DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+type UserRepository struct {
+	CreateUserFunc  func(ctx context.Context, user domain.User) (int64, error)
+	GetUserByIDFunc func(ctx context.Context, id int64) (domain.User, error)
+	UpdateUserFunc  func(ctx context.Context, id int64, version int64, update domain.UserUpdate) (domain.User, error)
+	DeleteUserFunc  func(ctx context.Context, id int64) error
+	ListUsersFunc   func(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+}
+
+func (m *UserRepository) CreateUser(ctx context.Context, user domain.User) (int64, error) {
+	if m.CreateUserFunc == nil {
+		panic("unexpected call to UserRepository.CreateUser")
+	}
+	return m.CreateUserFunc(ctx, user)
+}
+
+func (m *UserRepository) GetUserByID(ctx context.Context, id int64) (domain.User, error) {
+	if m.GetUserByIDFunc == nil {
+		panic("unexpected call to UserRepository.GetUserByID")
+	}
+	return m.GetUserByIDFunc(ctx, id)
+}
+
+func (m *UserRepository) UpdateUser(ctx context.Context, id int64, version int64, update domain.UserUpdate) (domain.User, error) {
+	if m.UpdateUserFunc == nil {
+		panic("unexpected call to UserRepository.UpdateUser")
+	}
+	return m.UpdateUserFunc(ctx, id, version, update)
+}
+
+func (m *UserRepository) DeleteUser(ctx context.Context, id int64) error {
+	if m.DeleteUserFunc == nil {
+		panic("unexpected call to UserRepository.DeleteUser")
+	}
+	return m.DeleteUserFunc(ctx, id)
+}
+
+func (m *UserRepository) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	if m.ListUsersFunc == nil {
+		panic("unexpected call to UserRepository.ListUsers")
+	}
+	return m.ListUsersFunc(ctx, filter)
+}
+
+type ProductRepository struct {
+	CreateProductFunc        func(ctx context.Context, product domain.Product) (int64, error)
+	GetProductByIDFunc       func(ctx context.Context, id int64) (domain.Product, error)
+	GetProductAtFunc         func(ctx context.Context, id int64, at time.Time) (domain.Product, error)
+	ListProductVersionsFunc  func(ctx context.Context, productID int64) ([]domain.ProductVersion, error)
+	UpdateProductFunc        func(ctx context.Context, id int64, version int64, update domain.ProductUpdate) (domain.Product, error)
+	AdjustProductStockFunc   func(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error)
+	ListStockMovementsFunc   func(ctx context.Context, productID int64, before int64, limit int) ([]domain.StockMovement, error)
+	ReconcileStockFunc       func(ctx context.Context) ([]domain.StockDiscrepancy, error)
+	ListLowStockProductsFunc func(ctx context.Context) ([]domain.Product, error)
+	ImportProductsFunc       func(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, []domain.LowStockAlert, error)
+}
+
+func (m *ProductRepository) CreateProduct(ctx context.Context, product domain.Product) (int64, error) {
+	if m.CreateProductFunc == nil {
+		panic("unexpected call to ProductRepository.CreateProduct")
+	}
+	return m.CreateProductFunc(ctx, product)
+}
+
+func (m *ProductRepository) GetProductByID(ctx context.Context, id int64) (domain.Product, error) {
+	if m.GetProductByIDFunc == nil {
+		panic("unexpected call to ProductRepository.GetProductByID")
+	}
+	return m.GetProductByIDFunc(ctx, id)
+}
+
+func (m *ProductRepository) GetProductAt(ctx context.Context, id int64, at time.Time) (domain.Product, error) {
+	if m.GetProductAtFunc == nil {
+		panic("unexpected call to ProductRepository.GetProductAt")
+	}
+	return m.GetProductAtFunc(ctx, id, at)
+}
+
+func (m *ProductRepository) ListProductVersions(ctx context.Context, productID int64) ([]domain.ProductVersion, error) {
+	if m.ListProductVersionsFunc == nil {
+		panic("unexpected call to ProductRepository.ListProductVersions")
+	}
+	return m.ListProductVersionsFunc(ctx, productID)
+}
+
+func (m *ProductRepository) UpdateProduct(ctx context.Context, id int64, version int64, update domain.ProductUpdate) (domain.Product, error) {
+	if m.UpdateProductFunc == nil {
+		panic("unexpected call to ProductRepository.UpdateProduct")
+	}
+	return m.UpdateProductFunc(ctx, id, version, update)
+}
+
+func (m *ProductRepository) AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error) {
+	if m.AdjustProductStockFunc == nil {
+		panic("unexpected call to ProductRepository.AdjustProductStock")
+	}
+	return m.AdjustProductStockFunc(ctx, id, adjustment)
+}
+
+func (m *ProductRepository) ListStockMovements(ctx context.Context, productID int64, before int64, limit int) ([]domain.StockMovement, error) {
+	if m.ListStockMovementsFunc == nil {
+		panic("unexpected call to ProductRepository.ListStockMovements")
+	}
+	return m.ListStockMovementsFunc(ctx, productID, before, limit)
+}
+
+func (m *ProductRepository) ReconcileStock(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	if m.ReconcileStockFunc == nil {
+		panic("unexpected call to ProductRepository.ReconcileStock")
+	}
+	return m.ReconcileStockFunc(ctx)
+}
+
+func (m *ProductRepository) ListLowStockProducts(ctx context.Context) ([]domain.Product, error) {
+	if m.ListLowStockProductsFunc == nil {
+		panic("unexpected call to ProductRepository.ListLowStockProducts")
+	}
+	return m.ListLowStockProductsFunc(ctx)
+}
+
+func (m *ProductRepository) ImportProducts(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, []domain.LowStockAlert, error) {
+	if m.ImportProductsFunc == nil {
+		panic("unexpected call to ProductRepository.ImportProducts")
+	}
+	return m.ImportProductsFunc(ctx, rows, opts)
+}
+
+type OrderRepository struct {
+	CreateOrderFunc  func(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error)
+	GetOrderByIDFunc func(ctx context.Context, id int64) (domain.Order, error)
+	CancelOrderFunc  func(ctx context.Context, id int64) error
+	ExportOrdersFunc func(ctx context.Context, from time.Time, to time.Time, emit func(domain.OrderExportRow) error) (int, error)
+}
+
+func (m *OrderRepository) CreateOrder(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error) {
+	if m.CreateOrderFunc == nil {
+		panic("unexpected call to OrderRepository.CreateOrder")
+	}
+	return m.CreateOrderFunc(ctx, order)
+}
+
+func (m *OrderRepository) GetOrderByID(ctx context.Context, id int64) (domain.Order, error) {
+	if m.GetOrderByIDFunc == nil {
+		panic("unexpected call to OrderRepository.GetOrderByID")
+	}
+	return m.GetOrderByIDFunc(ctx, id)
+}
+
+func (m *OrderRepository) CancelOrder(ctx context.Context, id int64) error {
+	if m.CancelOrderFunc == nil {
+		panic("unexpected call to OrderRepository.CancelOrder")
+	}
+	return m.CancelOrderFunc(ctx, id)
+}
+
+func (m *OrderRepository) ExportOrders(ctx context.Context, from time.Time, to time.Time, emit func(domain.OrderExportRow) error) (int, error) {
+	if m.ExportOrdersFunc == nil {
+		panic("unexpected call to OrderRepository.ExportOrders")
+	}
+	return m.ExportOrdersFunc(ctx, from, to, emit)
+}
+
+type PromoRepository struct {
+	CreatePromoCodeFunc func(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	GetPromoCodeFunc    func(ctx context.Context, id int64) (domain.PromoCode, error)
+	ListPromoCodesFunc  func(ctx context.Context) ([]domain.PromoCode, error)
+}
+
+func (m *PromoRepository) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	if m.CreatePromoCodeFunc == nil {
+		panic("unexpected call to PromoRepository.CreatePromoCode")
+	}
+	return m.CreatePromoCodeFunc(ctx, promo)
+}
+
+func (m *PromoRepository) GetPromoCode(ctx context.Context, id int64) (domain.PromoCode, error) {
+	if m.GetPromoCodeFunc == nil {
+		panic("unexpected call to PromoRepository.GetPromoCode")
+	}
+	return m.GetPromoCodeFunc(ctx, id)
+}
+
+func (m *PromoRepository) ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error) {
+	if m.ListPromoCodesFunc == nil {
+		panic("unexpected call to PromoRepository.ListPromoCodes")
+	}
+	return m.ListPromoCodesFunc(ctx)
+}
+
+type WebhookRepository struct {
+	CreateWebhookSubscriptionFunc func(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	ListWebhookSubscriptionsFunc  func(ctx context.Context) ([]domain.WebhookSubscription, error)
+	GetWebhookSubscriptionFunc    func(ctx context.Context, id int64) (domain.WebhookSubscription, error)
+	DeleteWebhookSubscriptionFunc func(ctx context.Context, id int64) error
+	ListWebhookDeliveriesFunc     func(ctx context.Context, subscriptionID int64, before int64, limit int) ([]domain.WebhookDelivery, error)
+	GetWebhookDeliveryFunc        func(ctx context.Context, subscriptionID int64, id int64) (domain.WebhookDelivery, error)
+	RedeliverWebhookFunc          func(ctx context.Context, subscriptionID int64, id int64) (domain.WebhookDelivery, error)
+}
+
+func (m *WebhookRepository) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if m.CreateWebhookSubscriptionFunc == nil {
+		panic("unexpected call to WebhookRepository.CreateWebhookSubscription")
+	}
+	return m.CreateWebhookSubscriptionFunc(ctx, sub)
+}
+
+func (m *WebhookRepository) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	if m.ListWebhookSubscriptionsFunc == nil {
+		panic("unexpected call to WebhookRepository.ListWebhookSubscriptions")
+	}
+	return m.ListWebhookSubscriptionsFunc(ctx)
+}
+
+func (m *WebhookRepository) GetWebhookSubscription(ctx context.Context, id int64) (domain.WebhookSubscription, error) {
+	if m.GetWebhookSubscriptionFunc == nil {
+		panic("unexpected call to WebhookRepository.GetWebhookSubscription")
+	}
+	return m.GetWebhookSubscriptionFunc(ctx, id)
+}
+
+func (m *WebhookRepository) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	if m.DeleteWebhookSubscriptionFunc == nil {
+		panic("unexpected call to WebhookRepository.DeleteWebhookSubscription")
+	}
+	return m.DeleteWebhookSubscriptionFunc(ctx, id)
+}
+
+func (m *WebhookRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, before int64, limit int) ([]domain.WebhookDelivery, error) {
+	if m.ListWebhookDeliveriesFunc == nil {
+		panic("unexpected call to WebhookRepository.ListWebhookDeliveries")
+	}
+	return m.ListWebhookDeliveriesFunc(ctx, subscriptionID, before, limit)
+}
+
+func (m *WebhookRepository) GetWebhookDelivery(ctx context.Context, subscriptionID int64, id int64) (domain.WebhookDelivery, error) {
+	if m.GetWebhookDeliveryFunc == nil {
+		panic("unexpected call to WebhookRepository.GetWebhookDelivery")
+	}
+	return m.GetWebhookDeliveryFunc(ctx, subscriptionID, id)
+}
+
+func (m *WebhookRepository) RedeliverWebhook(ctx context.Context, subscriptionID int64, id int64) (domain.WebhookDelivery, error) {
+	if m.RedeliverWebhookFunc == nil {
+		panic("unexpected call to WebhookRepository.RedeliverWebhook")
+	}
+	return m.RedeliverWebhookFunc(ctx, subscriptionID, id)
+}
+
+type AuditRepository struct {
+	ListAuditEntriesFunc func(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+func (m *AuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if m.ListAuditEntriesFunc == nil {
+		panic("unexpected call to AuditRepository.ListAuditEntries")
+	}
+	return m.ListAuditEntriesFunc(ctx, filter)
+}
+
+type ReportRepository struct {
+	RevenueByPeriodFunc func(ctx context.Context, filter domain.ReportFilter) ([]domain.RevenueRow, error)
+	TopProductsFunc     func(ctx context.Context, filter domain.ReportFilter) ([]domain.ProductSales, error)
+	TopCustomersFunc    func(ctx context.Context, filter domain.ReportFilter) ([]domain.CustomerSales, error)
+}
+
+func (m *ReportRepository) RevenueByPeriod(ctx context.Context, filter domain.ReportFilter) ([]domain.RevenueRow, error) {
+	if m.RevenueByPeriodFunc == nil {
+		panic("unexpected call to ReportRepository.RevenueByPeriod")
+	}
+	return m.RevenueByPeriodFunc(ctx, filter)
+}
+
+func (m *ReportRepository) TopProducts(ctx context.Context, filter domain.ReportFilter) ([]domain.ProductSales, error) {
+	if m.TopProductsFunc == nil {
+		panic("unexpected call to ReportRepository.TopProducts")
+	}
+	return m.TopProductsFunc(ctx, filter)
+}
+
+func (m *ReportRepository) TopCustomers(ctx context.Context, filter domain.ReportFilter) ([]domain.CustomerSales, error) {
+	if m.TopCustomersFunc == nil {
+		panic("unexpected call to ReportRepository.TopCustomers")
+	}
+	return m.TopCustomersFunc(ctx, filter)
+}
+
+type Transactor struct {
+	WithinTxFunc    func(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommitFunc func(ctx context.Context, f func())
+}
+
+func (m *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.WithinTxFunc == nil {
+		panic("unexpected call to Transactor.WithinTx")
+	}
+	return m.WithinTxFunc(ctx, fn)
+}
+
+func (m *Transactor) AfterCommit(ctx context.Context, f func()) {
+	if m.AfterCommitFunc == nil {
+		panic("unexpected call to Transactor.AfterCommit")
+	}
+	m.AfterCommitFunc(ctx, f)
+}
+
+type Repository struct {
+	UserRepository
+	ProductRepository
+	OrderRepository
+	PromoRepository
+	WebhookRepository
+	AuditRepository
+	ReportRepository
+	Transactor
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+const lowStockNotifyTimeout = 30 * time.Second
+
+type OrderService interface {
+	CreateOrder(ctx context.Context, order domain.NewOrder) (int64, error)
+	GetOrderByID(ctx context.Context, id int64) (domain.Order, error)
+	CancelOrder(ctx context.Context, id int64) (domain.Order, error)
+	ExportOrders(ctx context.Context, from, to time.Time, emit func(domain.OrderExportRow) error) error
+}
+
+func (s *service) CreateOrder(ctx context.Context, order domain.NewOrder) (int64, error) {
+	s.logger.Debug("Creating order", "user_id", order.UserID, "items", len(order.Items))
+	id, alerts, err := s.repo.CreateOrder(ctx, order)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Order references a missing user or product", "user_id", order.UserID)
+			return 0, fmt.Errorf("%w: user %d or one of the ordered products does not exist", ErrNotFound, order.UserID)
+		}
+		if errors.Is(err, domain.ErrInsufficientStock) {
+			s.logger.Error(nil, "Not enough stock for order", "user_id", order.UserID, "reason", err.Error())
+			return 0, fmt.Errorf("%w: not enough stock for one of the ordered products", ErrConflict)
+		}
+		if errors.Is(err, domain.ErrPromoNotApplicable) {
+			s.logger.Error(nil, "Promo code rejected", "user_id", order.UserID, "reason", err.Error())
+			return 0, fmt.Errorf("%w: %s", ErrValidation, err)
+		}
+		if errors.Is(err, domain.ErrPromoExhausted) {
+			s.logger.Error(nil, "Promo code used up", "user_id", order.UserID, "reason", err.Error())
+			return 0, fmt.Errorf("%w: %s", ErrConflict, err)
+		}
+		s.logger.Error(err, "Failed to create order", "user_id", order.UserID)
+		return 0, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	s.logger.Info("Order created successfully", "id", id)
+	if len(alerts) > 0 {
+		s.repo.AfterCommit(ctx, func() {
+			go s.notifyLowStock(context.WithoutCancel(ctx), alerts)
+		})
+	}
+	return id, nil
+}
+
+// notifyLowStock runs after the order has committed, so a slow or failing
+// notifier never holds up or rolls back the order itself.
+func (s *service) notifyLowStock(ctx context.Context, alerts []domain.LowStockAlert) {
+	ctx, cancel := context.WithTimeout(ctx, lowStockNotifyTimeout)
+	defer cancel()
+
+	for _, alert := range alerts {
+		if err := s.notifier.NotifyLowStock(ctx, alert); err != nil {
+			s.logger.Error(err, "Failed to deliver low stock alert", "product_id", alert.ProductID, "order_id", alert.OrderID)
+		}
+	}
+}
+
+func (s *service) GetOrderByID(ctx context.Context, id int64) (domain.Order, error) {
+	s.logger.Debug("Fetching order", "id", id)
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Order not found", "id", id)
+			return domain.Order{}, fmt.Errorf("%w: order with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to get order", "id", id)
+		return domain.Order{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	s.logger.Debug("Order fetched successfully", "id", id)
+	return order, nil
+}
+
+func (s *service) CancelOrder(ctx context.Context, id int64) (domain.Order, error) {
+	s.logger.Debug("Cancelling order", "id", id)
+	var order domain.Order
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CancelOrder(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		order, err = s.repo.GetOrderByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Order not found", "id", id)
+			return domain.Order{}, fmt.Errorf("%w: order with id %d not found", ErrNotFound, id)
+		}
+		if errors.Is(err, domain.ErrOrderCancelled) {
+			s.logger.Error(nil, "Order already cancelled", "id", id)
+			return domain.Order{}, fmt.Errorf("%w: order with id %d is already cancelled", ErrConflict, id)
+		}
+		s.logger.Error(err, "Failed to cancel order", "id", id)
+		return domain.Order{}, fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	s.logger.Info("Order cancelled successfully", "id", id)
+	return order, nil
+}
+
+func (s *service) ExportOrders(ctx context.Context, from, to time.Time, emit func(domain.OrderExportRow) error) error {
+	s.logger.Debug("Exporting orders", "from", from, "to", to)
+	count, err := s.repo.ExportOrders(ctx, from, to, emit)
+	if err != nil {
+		s.logger.Error(err, "Failed to export orders", "from", from, "to", to, "rows", count)
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	s.logger.Info("Orders exported successfully", "from", from, "to", to, "rows", count)
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+	"pet-project/internal/service/mocks"
+	"pet-project/internal/storage/memory"
+)
+
+// fakeTransactor runs units of work directly and holds back AfterCommit
+// work until commit is called, as a real transaction would.
+type fakeTransactor struct {
+	inTx    bool
+	pending []func()
+}
+
+func (tx *fakeTransactor) mock() mocks.Transactor {
+	return mocks.Transactor{
+		WithinTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+			tx.inTx = true
+			defer func() { tx.inTx = false }()
+			return fn(ctx)
+		},
+		AfterCommitFunc: func(ctx context.Context, f func()) {
+			tx.pending = append(tx.pending, f)
+		},
+	}
+}
+
+func (tx *fakeTransactor) commit() {
+	for _, f := range tx.pending {
+		f()
+	}
+	tx.pending = nil
+}
+
+func TestCreateOrderNotifiesLowStockAfterCommit(t *testing.T) {
+	tx := &fakeTransactor{}
+	repo := &mocks.Repository{
+		OrderRepository: mocks.OrderRepository{
+			CreateOrderFunc: func(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error) {
+				return 10, []domain.LowStockAlert{{ProductID: 3, Quantity: 1, ReorderThreshold: 5, OrderID: 10}}, nil
+			},
+		},
+		Transactor: tx.mock(),
+	}
+	notifier := fakeNotifier{alerts: make(chan domain.LowStockAlert, 1)}
+	s := New(repo, notifier, logger.New("test"))
+
+	id, err := s.CreateOrder(context.Background(), domain.NewOrder{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 10 {
+		t.Errorf("id = %d, want 10", id)
+	}
+
+	select {
+	case alert := <-notifier.alerts:
+		t.Fatalf("alert for product %d sent before commit", alert.ProductID)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tx.commit()
+	select {
+	case alert := <-notifier.alerts:
+		if alert.ProductID != 3 || alert.OrderID != 10 {
+			t.Errorf("alert = %+v", alert)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no alert after commit")
+	}
+}
+
+func TestLowStockNotifiedOnlyOnCrossingThreshold(t *testing.T) {
+	ctx := context.Background()
+	// The memory store decides when stock crosses the threshold; it backs
+	// only the calls an order makes.
+	store := memory.New(config.Tax{DefaultClass: "standard", Classes: map[string]float64{"standard": 0.2}})
+	repo := &mocks.Repository{
+		OrderRepository: mocks.OrderRepository{CreateOrderFunc: store.CreateOrder},
+		Transactor:      mocks.Transactor{WithinTxFunc: store.WithinTx, AfterCommitFunc: store.AfterCommit},
+	}
+	notifier := fakeNotifier{alerts: make(chan domain.LowStockAlert, 10)}
+	s := New(repo, notifier, logger.New("test"))
+
+	userID, err := store.CreateUser(ctx, domain.User{FirstName: "Low", LastName: "Stock", Age: 30, Password: "password123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	threshold := 5
+	productID, err := store.CreateProduct(ctx, domain.Product{
+		SKU:              "low-stock",
+		Description:      "Low stock product",
+		Quantity:         10,
+		Price:            10,
+		TaxClass:         "standard",
+		ReorderThreshold: &threshold,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orders := []struct {
+		quantity int
+		notified bool
+	}{
+		{3, false}, // 10 to 7, still above
+		{2, true},  // 7 to 5, reaches the threshold
+		{1, false}, // 5 to 4, already below
+	}
+	for i, o := range orders {
+		id, err := s.CreateOrder(ctx, domain.NewOrder{UserID: userID, Items: []domain.OrderItem{{ProductID: productID, Quantity: o.quantity}}})
+		if err != nil {
+			t.Fatalf("order %d: %v", i+1, err)
+		}
+
+		select {
+		case alert := <-notifier.alerts:
+			if !o.notified {
+				t.Errorf("order %d: unexpected alert %+v", i+1, alert)
+			} else if alert.ProductID != productID || alert.OrderID != id || alert.Quantity != 5 || alert.ReorderThreshold != 5 {
+				t.Errorf("order %d: alert = %+v", i+1, alert)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if o.notified {
+				t.Errorf("order %d: no alert on crossing the threshold", i+1)
+			}
+		}
+	}
+}
+
+func TestCancelOrderReadsBackInSameUnitOfWork(t *testing.T) {
+	tx := &fakeTransactor{}
+	var cancelled bool
+	repo := &mocks.Repository{
+		OrderRepository: mocks.OrderRepository{
+			CancelOrderFunc: func(ctx context.Context, id int64) error {
+				if !tx.inTx {
+					t.Error("order cancelled outside a unit of work")
+				}
+				cancelled = true
+				return nil
+			},
+			GetOrderByIDFunc: func(ctx context.Context, id int64) (domain.Order, error) {
+				if !tx.inTx || !cancelled {
+					t.Error("order read back outside the unit of work that cancelled it")
+				}
+				return domain.Order{ID: id, Status: domain.OrderStatusCancelled}, nil
+			},
+		},
+		Transactor: tx.mock(),
+	}
+
+	order, err := newTestService(repo).CancelOrder(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 4 || order.Status != domain.OrderStatusCancelled {
+		t.Errorf("order = %+v", order)
+	}
+}
+
+func TestCancelOrderAlreadyCancelled(t *testing.T) {
+	tx := &fakeTransactor{}
+	repo := &mocks.Repository{
+		OrderRepository: mocks.OrderRepository{
+			CancelOrderFunc: func(ctx context.Context, id int64) error {
+				return domain.ErrOrderCancelled
+			},
+		},
+		Transactor: tx.mock(),
+	}
+
+	_, err := newTestService(repo).CancelOrder(context.Background(), 4)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want %v", err, ErrConflict)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+//go:generate go run pet-project/internal/mockgen -src ports.go -out mocks/ports.go -pkg mocks
+
+// The ports below are what the service needs from storage. Implementations
+// report the outcomes the service handles with the errors in domain, such as
+// domain.ErrNotFound and domain.ErrAlreadyExists, wrapped or not.
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user domain.User) (int64, error)
+	GetUserByID(ctx context.Context, id int64) (domain.User, error)
+	UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error)
+	DeleteUser(ctx context.Context, id int64) error
+	ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+}
+
+type ProductRepository interface {
+	CreateProduct(ctx context.Context, product domain.Product) (int64, error)
+	GetProductByID(ctx context.Context, id int64) (domain.Product, error)
+	GetProductAt(ctx context.Context, id int64, at time.Time) (domain.Product, error)
+	ListProductVersions(ctx context.Context, productID int64) ([]domain.ProductVersion, error)
+	UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error)
+	AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error)
+	ListStockMovements(ctx context.Context, productID, before int64, limit int) ([]domain.StockMovement, error)
+	ReconcileStock(ctx context.Context) ([]domain.StockDiscrepancy, error)
+	ListLowStockProducts(ctx context.Context) ([]domain.Product, error)
+	ImportProducts(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, []domain.LowStockAlert, error)
+}
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error)
+	GetOrderByID(ctx context.Context, id int64) (domain.Order, error)
+	CancelOrder(ctx context.Context, id int64) error
+	ExportOrders(ctx context.Context, from, to time.Time, emit func(domain.OrderExportRow) error) (int, error)
+}
+
+type PromoRepository interface {
+	CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	GetPromoCode(ctx context.Context, id int64) (domain.PromoCode, error)
+	ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error)
+}
+
+type WebhookRepository interface {
+	CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID, before int64, limit int) ([]domain.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error)
+}
+
+type AuditRepository interface {
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+type ReportRepository interface {
+	RevenueByPeriod(ctx context.Context, filter domain.ReportFilter) ([]domain.RevenueRow, error)
+	TopProducts(ctx context.Context, filter domain.ReportFilter) ([]domain.ProductSales, error)
+	TopCustomers(ctx context.Context, filter domain.ReportFilter) ([]domain.CustomerSales, error)
+}
+
+// Transactor runs units of work. Repository calls made with the context fn
+// receives share its transaction; fn may be run more than once, so effects
+// outside the repositories go through AfterCommit, which runs f once the
+// unit of work ctx belongs to has committed, or at once outside of one.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, f func())
+}
+
+type Repository interface {
+	UserRepository
+	ProductRepository
+	OrderRepository
+	PromoRepository
+	WebhookRepository
+	AuditRepository
+	ReportRepository
+	Transactor
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+type ProductService interface {
+	CreateProduct(ctx context.Context, product domain.Product) (int64, error)
+	GetProductByID(ctx context.Context, id int64) (domain.Product, error)
+	GetProductAt(ctx context.Context, id int64, at time.Time) (domain.Product, error)
+	ListProductVersions(ctx context.Context, id int64) (domain.ProductVersionList, error)
+	UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error)
+	AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error)
+	ListStockMovements(ctx context.Context, productID int64, cursor string, limit int) (domain.StockMovementPage, error)
+	ReconcileStock(ctx context.Context) (domain.StockReconciliation, error)
+	ListLowStockProducts(ctx context.Context) ([]domain.Product, error)
+	ImportProducts(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, error)
+}
+
+func (s *service) CreateProduct(ctx context.Context, product domain.Product) (int64, error) {
+	s.logger.Debug("Creating product", "description", product.Description)
+	id, err := s.repo.CreateProduct(ctx, product)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownTaxClass) {
+			s.logger.Error(nil, "Unknown tax class", "tax_class", product.TaxClass)
+			return 0, fmt.Errorf("%w: %s", ErrValidation, err)
+		}
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			s.logger.Error(nil, "Product already exists", "sku", product.SKU)
+			return 0, fmt.Errorf("%w: product with sku %s already exists", ErrConflict, product.SKU)
+		}
+		s.logger.Error(err, "Failed to create product", "description", product.Description)
+		return 0, fmt.Errorf("failed to create product: %w", err)
+	}
+
+	s.logger.Info("Product created successfully", "id", id)
+	return id, nil
+}
+
+func (s *service) GetProductByID(ctx context.Context, id int64) (domain.Product, error) {
+	s.logger.Debug("Fetching product", "id", id)
+	product, err := s.repo.GetProductByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Product not found", "id", id)
+			return domain.Product{}, fmt.Errorf("%w: product with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to get product", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	s.logger.Debug("Product fetched successfully", "id", id)
+	return product, nil
+}
+
+func (s *service) GetProductAt(ctx context.Context, id int64, at time.Time) (domain.Product, error) {
+	s.logger.Debug("Fetching product version", "id", id, "at", at)
+	product, err := s.repo.GetProductAt(ctx, id, at)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Product not found", "id", id, "at", at)
+			return domain.Product{}, fmt.Errorf("%w: product with id %d did not exist at %s", ErrNotFound, id, at.Format(time.RFC3339))
+		}
+		s.logger.Error(err, "Failed to get product version", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to get product version: %w", err)
+	}
+
+	s.logger.Debug("Product version fetched", "id", id, "version", product.Version)
+	return product, nil
+}
+
+func (s *service) ListProductVersions(ctx context.Context, id int64) (domain.ProductVersionList, error) {
+	s.logger.Debug("Listing product versions", "id", id)
+	versions, err := s.repo.ListProductVersions(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Product not found", "id", id)
+			return domain.ProductVersionList{}, fmt.Errorf("%w: product with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to list product versions", "id", id)
+		return domain.ProductVersionList{}, fmt.Errorf("failed to list product versions: %w", err)
+	}
+
+	s.logger.Debug("Product versions listed", "id", id, "count", len(versions))
+	return domain.ProductVersionList{Versions: versions}, nil
+}
+
+func (s *service) UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error) {
+	s.logger.Debug("Updating product", "id", id, "version", version)
+	product, err := s.repo.UpdateProduct(ctx, id, version, update)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Product not found", "id", id)
+			return domain.Product{}, fmt.Errorf("%w: product with id %d not found", ErrNotFound, id)
+		}
+		if errors.Is(err, domain.ErrVersionConflict) {
+			s.logger.Error(nil, "Product was modified concurrently", "id", id, "version", version)
+			return domain.Product{}, fmt.Errorf("%w: product with id %d was modified, reload it and retry", ErrPreconditionFailed, id)
+		}
+		if errors.Is(err, domain.ErrUnknownTaxClass) {
+			s.logger.Error(nil, "Unknown tax class", "id", id, "tax_class", *update.TaxClass)
+			return domain.Product{}, fmt.Errorf("%w: %s", ErrValidation, err)
+		}
+		s.logger.Error(err, "Failed to update product", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	s.logger.Info("Product updated successfully", "id", id)
+	return product, nil
+}
+
+func (s *service) AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error) {
+	s.logger.Debug("Adjusting product stock", "id", id, "delta", adjustment.Delta)
+	if adjustment.Kind == "" {
+		adjustment.Kind = domain.MovementCorrection
+		if adjustment.Delta > 0 {
+			adjustment.Kind = domain.MovementRestock
+		}
+	}
+
+	product, err := s.repo.AdjustProductStock(ctx, id, adjustment)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Product not found", "id", id)
+			return domain.Product{}, fmt.Errorf("%w: product with id %d not found", ErrNotFound, id)
+		}
+		if errors.Is(err, domain.ErrInsufficientStock) {
+			s.logger.Error(nil, "Stock adjustment would go below zero", "id", id, "delta", adjustment.Delta)
+			return domain.Product{}, fmt.Errorf("%w: stock of product %d cannot go below zero", ErrConflict, id)
+		}
+		s.logger.Error(err, "Failed to adjust product stock", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to adjust product stock: %w", err)
+	}
+
+	s.logger.Info("Product stock adjusted successfully", "id", id, "quantity", product.Quantity)
+	return product, nil
+}
+
+func (s *service) ListStockMovements(ctx context.Context, productID int64, cursor string, limit int) (domain.StockMovementPage, error) {
+	s.logger.Debug("Listing stock movements", "product_id", productID)
+	var before int64
+	if cursor != "" {
+		var err error
+		before, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return domain.StockMovementPage{}, fmt.Errorf("%w: invalid cursor", ErrValidation)
+		}
+	}
+
+	movements, err := s.repo.ListStockMovements(ctx, productID, before, limit+1)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Product not found", "id", productID)
+			return domain.StockMovementPage{}, fmt.Errorf("%w: product with id %d not found", ErrNotFound, productID)
+		}
+		s.logger.Error(err, "Failed to list stock movements", "product_id", productID)
+		return domain.StockMovementPage{}, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+
+	page := domain.StockMovementPage{Movements: movements}
+	if len(movements) > limit {
+		page.Movements = movements[:limit]
+		page.NextCursor = strconv.FormatInt(page.Movements[limit-1].ID, 10)
+	}
+	return page, nil
+}
+
+func (s *service) ReconcileStock(ctx context.Context) (domain.StockReconciliation, error) {
+	s.logger.Debug("Reconciling stock ledger")
+	discrepancies, err := s.repo.ReconcileStock(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to reconcile stock")
+		return domain.StockReconciliation{}, fmt.Errorf("failed to reconcile stock: %w", err)
+	}
+
+	if len(discrepancies) > 0 {
+		s.logger.Error(nil, "Stock ledger does not match product quantities", "products", len(discrepancies))
+	}
+	return domain.StockReconciliation{
+		Consistent:    len(discrepancies) == 0,
+		Discrepancies: discrepancies,
+	}, nil
+}
+
+func (s *service) ListLowStockProducts(ctx context.Context) ([]domain.Product, error) {
+	s.logger.Debug("Listing low stock products")
+	products, err := s.repo.ListLowStockProducts(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to list low stock products")
+		return nil, fmt.Errorf("failed to list low stock products: %w", err)
+	}
+
+	s.logger.Debug("Low stock products listed successfully", "count", len(products))
+	return products, nil
+}
+
+func (s *service) ImportProducts(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, error) {
+	s.logger.Debug("Importing products", "rows", len(rows), "dry_run", opts.DryRun, "upsert", opts.Upsert)
+	result, alerts, err := s.repo.ImportProducts(ctx, rows, opts)
+	if err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			s.logger.Error(nil, "Imported sku was created concurrently")
+			return domain.ProductImportResult{}, fmt.Errorf("%w: a product with an imported sku was created meanwhile, retry the import", ErrConflict)
+		}
+		s.logger.Error(err, "Failed to import products")
+		return domain.ProductImportResult{}, fmt.Errorf("failed to import products: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		s.logger.Error(nil, "Product import rejected", "errors", len(result.Errors))
+		return result, nil
+	}
+
+	s.logger.Info("Products imported successfully", "created", result.Created, "updated", result.Updated, "dry_run", opts.DryRun)
+	if len(alerts) > 0 {
+		s.repo.AfterCommit(ctx, func() {
+			go s.notifyLowStock(context.WithoutCancel(ctx), alerts)
+		})
+	}
+	return result, nil
+}
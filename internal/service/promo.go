@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pet-project/internal/domain"
+)
+
+type PromoService interface {
+	CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	GetPromoCode(ctx context.Context, id int64) (domain.PromoCode, error)
+	ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error)
+}
+
+func (s *service) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	s.logger.Debug("Creating promo code", "code", promo.Code)
+	created, err := s.repo.CreatePromoCode(ctx, promo)
+	if err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			s.logger.Error(nil, "Promo code already exists", "code", promo.Code)
+			return domain.PromoCode{}, fmt.Errorf("%w: promo code %s already exists", ErrConflict, promo.Code)
+		}
+		s.logger.Error(err, "Failed to create promo code", "code", promo.Code)
+		return domain.PromoCode{}, fmt.Errorf("failed to create promo code: %w", err)
+	}
+
+	s.logger.Info("Promo code created successfully", "id", created.ID)
+	return created, nil
+}
+
+func (s *service) GetPromoCode(ctx context.Context, id int64) (domain.PromoCode, error) {
+	s.logger.Debug("Fetching promo code", "id", id)
+	promo, err := s.repo.GetPromoCode(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Promo code not found", "id", id)
+			return domain.PromoCode{}, fmt.Errorf("%w: promo code with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to get promo code", "id", id)
+		return domain.PromoCode{}, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return promo, nil
+}
+
+func (s *service) ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error) {
+	s.logger.Debug("Listing promo codes")
+	promos, err := s.repo.ListPromoCodes(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to list promo codes")
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	return promos, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pet-project/internal/domain"
+)
+
+type ReportService interface {
+	RevenueReport(ctx context.Context, filter domain.ReportFilter) (domain.RevenueReport, error)
+	TopProductsReport(ctx context.Context, filter domain.ReportFilter) (domain.TopProductsReport, error)
+	TopCustomersReport(ctx context.Context, filter domain.ReportFilter) (domain.TopCustomersReport, error)
+}
+
+func (s *service) RevenueReport(ctx context.Context, filter domain.ReportFilter) (domain.RevenueReport, error) {
+	s.logger.Debug("Building revenue report", "period", filter.Period, "time_zone", filter.TimeZone)
+	rows, err := s.repo.RevenueByPeriod(ctx, filter)
+	if err != nil {
+		s.logger.Error(err, "Failed to build revenue report")
+		return domain.RevenueReport{}, fmt.Errorf("failed to build revenue report: %w", err)
+	}
+
+	return domain.RevenueReport{
+		From:     filter.From,
+		To:       filter.To,
+		Period:   filter.Period,
+		TimeZone: filter.TimeZone,
+		Rows:     rows,
+	}, nil
+}
+
+func (s *service) TopProductsReport(ctx context.Context, filter domain.ReportFilter) (domain.TopProductsReport, error) {
+	s.logger.Debug("Building top products report", "limit", filter.Limit)
+	products, err := s.repo.TopProducts(ctx, filter)
+	if err != nil {
+		s.logger.Error(err, "Failed to build top products report")
+		return domain.TopProductsReport{}, fmt.Errorf("failed to build top products report: %w", err)
+	}
+
+	return domain.TopProductsReport{From: filter.From, To: filter.To, Products: products}, nil
+}
+
+func (s *service) TopCustomersReport(ctx context.Context, filter domain.ReportFilter) (domain.TopCustomersReport, error) {
+	s.logger.Debug("Building top customers report", "limit", filter.Limit)
+	customers, err := s.repo.TopCustomers(ctx, filter)
+	if err != nil {
+		s.logger.Error(err, "Failed to build top customers report")
+		return domain.TopCustomersReport{}, fmt.Errorf("failed to build top customers report: %w", err)
+	}
+
+	return domain.TopCustomersReport{From: filter.From, To: filter.To, Customers: customers}, nil
+}
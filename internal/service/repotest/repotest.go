@@ -0,0 +1,610 @@
+// Package repotest checks that a storage backend behaves as the service
+// expects of its repositories: the domain errors it reports, stock that
+// never goes below zero, and units of work that commit or roll back whole.
+// A backend's tests call Run with a constructor for it.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/outbox"
+	"pet-project/internal/service"
+)
+
+// Repository is the part of service.Repository the suite covers. Backends
+// with a transactional outbox also implement outbox.Store, and the suite
+// then checks that events of an aggregate are claimed in order.
+type Repository interface {
+	CreateUser(ctx context.Context, user domain.User) (int64, error)
+	GetUserByID(ctx context.Context, id int64) (domain.User, error)
+	UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error)
+	DeleteUser(ctx context.Context, id int64) error
+
+	CreateProduct(ctx context.Context, product domain.Product) (int64, error)
+	GetProductByID(ctx context.Context, id int64) (domain.Product, error)
+	UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error)
+	AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error)
+
+	CreateOrder(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error)
+	GetOrderByID(ctx context.Context, id int64) (domain.Order, error)
+	CancelOrder(ctx context.Context, id int64) error
+
+	service.Transactor
+}
+
+// TaxClass is the tax class the suite gives its products; backends under
+// test must have it configured.
+const TaxClass = "standard"
+
+// missingID is an ID no backend under test has handed out.
+const missingID = 1 << 62
+
+// Run runs the suite against the repository newRepo returns. The suite makes
+// its data unique, so the repository may be shared and need not be empty.
+func Run(t *testing.T, newRepo func(t *testing.T) Repository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo Repository)
+	}{
+		{"UserRoundTrip", testUserRoundTrip},
+		{"DuplicateUser", testDuplicateUser},
+		{"MissingRecords", testMissingRecords},
+		{"DeletedUser", testDeletedUser},
+		{"StaleVersions", testStaleVersions},
+		{"DuplicateSKU", testDuplicateSKU},
+		{"StockAdjustmentBelowZero", testStockAdjustmentBelowZero},
+		{"OrderTakesStock", testOrderTakesStock},
+		{"FailedOrderLeavesStock", testFailedOrderLeavesStock},
+		{"CancelOrderReturnsStock", testCancelOrderReturnsStock},
+		{"UnitOfWorkRollsBack", testUnitOfWorkRollsBack},
+		{"UnitOfWorkCommits", testUnitOfWorkCommits},
+		{"ConcurrentOrdersNeverOversell", testConcurrentOrdersNeverOversell},
+		{"ConcurrentAdjustmentsNeverGoNegative", testConcurrentAdjustmentsNeverGoNegative},
+		{"OutboxOrderPerAggregate", testOutboxOrderPerAggregate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+var sequence atomic.Int64
+
+// unique returns a string no other call in this process returns, and that
+// earlier runs against the same database are unlikely to have used.
+func unique(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), sequence.Add(1))
+}
+
+func newUser(t *testing.T, repo Repository) domain.User {
+	t.Helper()
+	user := domain.User{FirstName: "Conformance", LastName: unique("user"), Age: 30, Password: "password123"}
+	id, err := repo.CreateUser(context.Background(), user)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	user.ID = id
+	return user
+}
+
+func newProduct(t *testing.T, repo Repository, quantity int) domain.Product {
+	t.Helper()
+	product := domain.Product{
+		SKU:         unique("sku"),
+		Description: "Conformance product",
+		Tags:        []string{"conformance"},
+		Quantity:    quantity,
+		Price:       10,
+		TaxClass:    TaxClass,
+	}
+	id, err := repo.CreateProduct(context.Background(), product)
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	product.ID = id
+	return product
+}
+
+func quantity(t *testing.T, repo Repository, productID int64) int {
+	t.Helper()
+	product, err := repo.GetProductByID(context.Background(), productID)
+	if err != nil {
+		t.Fatalf("GetProductByID: %v", err)
+	}
+	return product.Quantity
+}
+
+func wantErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s: err = %v, want %v", op, err, want)
+	}
+}
+
+func testUserRoundTrip(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+
+	got, err := repo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if got.FirstName != user.FirstName || got.LastName != user.LastName || got.Age != user.Age {
+		t.Errorf("GetUserByID = %+v, want %+v", got, user)
+	}
+	if got.FullName != user.FirstName+" "+user.LastName {
+		t.Errorf("FullName = %q", got.FullName)
+	}
+	if got.Password != "" {
+		t.Error("GetUserByID read the password back")
+	}
+
+	lastName := unique("renamed")
+	updated, err := repo.UpdateUser(ctx, user.ID, got.Version, domain.UserUpdate{LastName: &lastName})
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if updated.LastName != lastName || updated.FullName != user.FirstName+" "+lastName {
+		t.Errorf("UpdateUser = %+v", updated)
+	}
+	if updated.Version <= got.Version {
+		t.Errorf("version %d did not advance past %d", updated.Version, got.Version)
+	}
+}
+
+func testDuplicateUser(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+
+	_, err := repo.CreateUser(ctx, domain.User{FirstName: user.FirstName, LastName: user.LastName, Age: 40, Password: "password456"})
+	wantErr(t, "CreateUser", err, domain.ErrAlreadyExists)
+
+	other := newUser(t, repo)
+	current, err := repo.GetUserByID(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	_, err = repo.UpdateUser(ctx, other.ID, current.Version, domain.UserUpdate{LastName: &user.LastName})
+	wantErr(t, "UpdateUser", err, domain.ErrAlreadyExists)
+}
+
+func testMissingRecords(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	_, err := repo.GetUserByID(ctx, missingID)
+	wantErr(t, "GetUserByID", err, domain.ErrNotFound)
+	_, err = repo.UpdateUser(ctx, missingID, 1, domain.UserUpdate{})
+	wantErr(t, "UpdateUser", err, domain.ErrNotFound)
+	wantErr(t, "DeleteUser", repo.DeleteUser(ctx, missingID), domain.ErrNotFound)
+
+	_, err = repo.GetProductByID(ctx, missingID)
+	wantErr(t, "GetProductByID", err, domain.ErrNotFound)
+	_, err = repo.UpdateProduct(ctx, missingID, 1, domain.ProductUpdate{})
+	wantErr(t, "UpdateProduct", err, domain.ErrNotFound)
+	_, err = repo.AdjustProductStock(ctx, missingID, domain.StockAdjustment{Delta: 1, Kind: domain.MovementRestock})
+	wantErr(t, "AdjustProductStock", err, domain.ErrNotFound)
+
+	_, err = repo.GetOrderByID(ctx, missingID)
+	wantErr(t, "GetOrderByID", err, domain.ErrNotFound)
+	wantErr(t, "CancelOrder", repo.CancelOrder(ctx, missingID), domain.ErrNotFound)
+
+	user := newUser(t, repo)
+	_, _, err = repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{{ProductID: missingID, Quantity: 1}}})
+	wantErr(t, "CreateOrder for a missing product", err, domain.ErrNotFound)
+
+	product := newProduct(t, repo, 5)
+	_, _, err = repo.CreateOrder(ctx, domain.NewOrder{UserID: missingID, Items: []domain.OrderItem{{ProductID: product.ID, Quantity: 1}}})
+	wantErr(t, "CreateOrder for a missing user", err, domain.ErrNotFound)
+}
+
+func testDeletedUser(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+	if err := repo.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	_, err := repo.GetUserByID(ctx, user.ID)
+	wantErr(t, "GetUserByID", err, domain.ErrNotFound)
+	wantErr(t, "DeleteUser again", repo.DeleteUser(ctx, user.ID), domain.ErrNotFound)
+
+	product := newProduct(t, repo, 5)
+	_, _, err = repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{{ProductID: product.ID, Quantity: 1}}})
+	wantErr(t, "CreateOrder", err, domain.ErrNotFound)
+
+	// The name of a deleted user is free again.
+	if _, err := repo.CreateUser(ctx, domain.User{FirstName: user.FirstName, LastName: user.LastName, Age: 30, Password: "password123"}); err != nil {
+		t.Errorf("CreateUser with a deleted user's name: %v", err)
+	}
+}
+
+func testStaleVersions(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+	current, err := repo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	married := true
+	if _, err := repo.UpdateUser(ctx, user.ID, current.Version, domain.UserUpdate{IsMarried: &married}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	_, err = repo.UpdateUser(ctx, user.ID, current.Version, domain.UserUpdate{IsMarried: &married})
+	wantErr(t, "UpdateUser", err, domain.ErrVersionConflict)
+
+	product := newProduct(t, repo, 5)
+	stale, err := repo.GetProductByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetProductByID: %v", err)
+	}
+	if _, err := repo.AdjustProductStock(ctx, product.ID, domain.StockAdjustment{Delta: 1, Kind: domain.MovementRestock}); err != nil {
+		t.Fatalf("AdjustProductStock: %v", err)
+	}
+	price := 12.0
+	_, err = repo.UpdateProduct(ctx, product.ID, stale.Version, domain.ProductUpdate{Price: &price})
+	wantErr(t, "UpdateProduct", err, domain.ErrVersionConflict)
+}
+
+func testDuplicateSKU(t *testing.T, repo Repository) {
+	product := newProduct(t, repo, 1)
+	duplicate := product
+	duplicate.ID = 0
+	_, err := repo.CreateProduct(context.Background(), duplicate)
+	wantErr(t, "CreateProduct", err, domain.ErrAlreadyExists)
+}
+
+func testStockAdjustmentBelowZero(t *testing.T, repo Repository) {
+	product := newProduct(t, repo, 3)
+	_, err := repo.AdjustProductStock(context.Background(), product.ID, domain.StockAdjustment{Delta: -4, Kind: domain.MovementCorrection, Reason: "count"})
+	wantErr(t, "AdjustProductStock", err, domain.ErrInsufficientStock)
+	if got := quantity(t, repo, product.ID); got != 3 {
+		t.Errorf("quantity = %d, want 3", got)
+	}
+}
+
+func testOrderTakesStock(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+	product := newProduct(t, repo, 7)
+	current, err := repo.GetProductByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetProductByID: %v", err)
+	}
+	threshold := 5
+	if _, err := repo.UpdateProduct(ctx, product.ID, current.Version, domain.ProductUpdate{ReorderThreshold: &threshold}); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+
+	id, alerts, err := repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{{ProductID: product.ID, Quantity: 3}}})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if got := quantity(t, repo, product.ID); got != 4 {
+		t.Errorf("quantity = %d, want 4", got)
+	}
+	if len(alerts) != 1 || alerts[0].ProductID != product.ID || alerts[0].Quantity != 4 || alerts[0].OrderID != id {
+		t.Errorf("alerts = %+v, want one for product %d at 4", alerts, product.ID)
+	}
+
+	order, err := repo.GetOrderByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrderByID: %v", err)
+	}
+	if order.UserID != user.ID || order.Status != domain.OrderStatusCreated || order.Subtotal != 30 {
+		t.Errorf("order = %+v", order)
+	}
+	if len(order.OrderProduct) != 1 {
+		t.Fatalf("order has %d lines, want 1", len(order.OrderProduct))
+	}
+	if line := order.OrderProduct[0]; line.ProductID != product.ID || line.Quantity != 3 || line.Price != 10 || line.Description != product.Description {
+		t.Errorf("line = %+v", line)
+	}
+
+	// Stock already at the threshold raises no new alert.
+	_, alerts, err = repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{{ProductID: product.ID, Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("alerts = %+v, want none", alerts)
+	}
+}
+
+// An order that cannot be filled must leave every product as it was, even
+// those it could have taken stock from.
+func testFailedOrderLeavesStock(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+	plenty := newProduct(t, repo, 10)
+	scarce := newProduct(t, repo, 1)
+
+	_, _, err := repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{
+		{ProductID: plenty.ID, Quantity: 2},
+		{ProductID: scarce.ID, Quantity: 2},
+	}})
+	wantErr(t, "CreateOrder", err, domain.ErrInsufficientStock)
+	if got := quantity(t, repo, plenty.ID); got != 10 {
+		t.Errorf("quantity of %d = %d, want 10", plenty.ID, got)
+	}
+	if got := quantity(t, repo, scarce.ID); got != 1 {
+		t.Errorf("quantity of %d = %d, want 1", scarce.ID, got)
+	}
+}
+
+func testCancelOrderReturnsStock(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+	product := newProduct(t, repo, 5)
+	id, _, err := repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{{ProductID: product.ID, Quantity: 5}}})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	if err := repo.CancelOrder(ctx, id); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if got := quantity(t, repo, product.ID); got != 5 {
+		t.Errorf("quantity = %d, want 5", got)
+	}
+	order, err := repo.GetOrderByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrderByID: %v", err)
+	}
+	if order.Status != domain.OrderStatusCancelled || order.CancelledAt == nil {
+		t.Errorf("order = %+v, want it cancelled", order)
+	}
+
+	wantErr(t, "CancelOrder again", repo.CancelOrder(ctx, id), domain.ErrOrderCancelled)
+	if got := quantity(t, repo, product.ID); got != 5 {
+		t.Errorf("quantity after second cancel = %d, want 5", got)
+	}
+}
+
+func testUnitOfWorkRollsBack(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	product := newProduct(t, repo, 5)
+	failure := errors.New("fail after writing")
+	var userID int64
+	afterCommit := false
+
+	err := repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		userID, err = repo.CreateUser(ctx, domain.User{FirstName: "Conformance", LastName: unique("rolled-back"), Age: 30, Password: "password123"})
+		if err != nil {
+			return err
+		}
+		if _, err := repo.AdjustProductStock(ctx, product.ID, domain.StockAdjustment{Delta: -5, Kind: domain.MovementCorrection, Reason: "count"}); err != nil {
+			return err
+		}
+		repo.AfterCommit(ctx, func() { afterCommit = true })
+		return failure
+	})
+	wantErr(t, "WithinTx", err, failure)
+
+	_, err = repo.GetUserByID(ctx, userID)
+	wantErr(t, "GetUserByID of a rolled back user", err, domain.ErrNotFound)
+	if got := quantity(t, repo, product.ID); got != 5 {
+		t.Errorf("quantity = %d, want 5", got)
+	}
+	if afterCommit {
+		t.Error("AfterCommit work ran for a rolled back unit of work")
+	}
+}
+
+func testUnitOfWorkCommits(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	user := newUser(t, repo)
+	product := newProduct(t, repo, 5)
+	var orderID int64
+	afterCommit := false
+
+	err := repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		orderID, _, err = repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: []domain.OrderItem{{ProductID: product.ID, Quantity: 2}}})
+		if err != nil {
+			return err
+		}
+		// Reads in the unit of work see its writes.
+		current, err := repo.GetProductByID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if current.Quantity != 3 {
+			t.Errorf("quantity inside the unit of work = %d, want 3", current.Quantity)
+		}
+		if err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			return repo.CancelOrder(ctx, orderID)
+		}); err != nil {
+			return err
+		}
+		repo.AfterCommit(ctx, func() { afterCommit = true })
+		if afterCommit {
+			t.Error("AfterCommit work ran before the unit of work committed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+
+	order, err := repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetOrderByID: %v", err)
+	}
+	if order.Status != domain.OrderStatusCancelled {
+		t.Errorf("status = %q, want %q", order.Status, domain.OrderStatusCancelled)
+	}
+	if got := quantity(t, repo, product.ID); got != 5 {
+		t.Errorf("quantity = %d, want 5", got)
+	}
+	if !afterCommit {
+		t.Error("AfterCommit work did not run after commit")
+	}
+}
+
+// concurrency is how many goroutines the concurrency tests run at once.
+const concurrency = 20
+
+func testConcurrentOrdersNeverOversell(t *testing.T, repo Repository) {
+	const stock = 7
+	ctx := context.Background()
+	hot := newProduct(t, repo, stock)
+	cold := newProduct(t, repo, 1000)
+	users := make([]domain.User, concurrency)
+	for i := range users {
+		users[i] = newUser(t, repo)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		placed   atomic.Int64
+		stockOut atomic.Int64
+	)
+	for _, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Ordering the products in either order must not deadlock.
+			items := []domain.OrderItem{{ProductID: hot.ID, Quantity: 1}, {ProductID: cold.ID, Quantity: 1}}
+			if user.ID%2 == 0 {
+				items[0], items[1] = items[1], items[0]
+			}
+			_, _, err := repo.CreateOrder(ctx, domain.NewOrder{UserID: user.ID, Items: items})
+			switch {
+			case err == nil:
+				placed.Add(1)
+			case errors.Is(err, domain.ErrInsufficientStock):
+				stockOut.Add(1)
+			default:
+				t.Errorf("CreateOrder: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if placed.Load() != stock {
+		t.Errorf("%d orders placed for %d in stock", placed.Load(), stock)
+	}
+	if stockOut.Load() != concurrency-stock {
+		t.Errorf("%d orders out of stock, want %d", stockOut.Load(), concurrency-stock)
+	}
+	if got := quantity(t, repo, hot.ID); got != 0 {
+		t.Errorf("hot product quantity = %d, want 0", got)
+	}
+	if got, want := quantity(t, repo, cold.ID), 1000-int(placed.Load()); got != want {
+		t.Errorf("cold product quantity = %d, want %d", got, want)
+	}
+}
+
+func testConcurrentAdjustmentsNeverGoNegative(t *testing.T, repo Repository) {
+	const stock = 10
+	ctx := context.Background()
+	product := newProduct(t, repo, stock)
+
+	var (
+		wg      sync.WaitGroup
+		applied atomic.Int64
+	)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.AdjustProductStock(ctx, product.ID, domain.StockAdjustment{Delta: -3, Kind: domain.MovementCorrection, Reason: "shrinkage"})
+			switch {
+			case err == nil:
+				applied.Add(1)
+			case !errors.Is(err, domain.ErrInsufficientStock):
+				t.Errorf("AdjustProductStock: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if applied.Load() != stock/3 {
+		t.Errorf("%d adjustments of -3 applied to %d in stock", applied.Load(), stock)
+	}
+	if got := quantity(t, repo, product.ID); got != stock%3 {
+		t.Errorf("quantity = %d, want %d", got, stock%3)
+	}
+}
+
+// claimOwn claims every due event and returns those of the user aggregate
+// id. The others in a shared database are claimed too, and stay leased for
+// the rest of the suite.
+func claimOwn(t *testing.T, store outbox.Store, id int64) []domain.Event {
+	t.Helper()
+	var own []domain.Event
+	for {
+		events, err := store.ClaimOutboxEvents(context.Background(), 100, time.Hour)
+		if err != nil {
+			t.Fatalf("ClaimOutboxEvents: %v", err)
+		}
+		if len(events) == 0 {
+			return own
+		}
+		for _, e := range events {
+			if e.AggregateType == domain.AggregateUser && e.AggregateID == id {
+				own = append(own, e)
+			}
+		}
+	}
+}
+
+func testOutboxOrderPerAggregate(t *testing.T, repo Repository) {
+	store, ok := repo.(outbox.Store)
+	if !ok {
+		t.Skip("the backend has no outbox")
+	}
+
+	ctx := context.Background()
+	user := newUser(t, repo)
+	current, err := repo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	married := true
+	if _, err := repo.UpdateUser(ctx, user.ID, current.Version, domain.UserUpdate{IsMarried: &married}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if err := repo.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	claimed := claimOwn(t, store, user.ID)
+	if len(claimed) != 1 || claimed[0].Type != domain.EventUserRegistered {
+		t.Fatalf("first claim = %+v, want only %s", claimed, domain.EventUserRegistered)
+	}
+	registered := claimed[0]
+
+	// A leased event is not claimed again, and holds back the later ones.
+	if claimed := claimOwn(t, store, user.ID); len(claimed) != 0 {
+		t.Fatalf("claim during the lease = %+v, want none", claimed)
+	}
+
+	// A failed event is retried before any later one.
+	if err := store.RetryOutboxEvent(ctx, registered.ID, 0, "sink unavailable"); err != nil {
+		t.Fatalf("RetryOutboxEvent: %v", err)
+	}
+	claimed = claimOwn(t, store, user.ID)
+	if len(claimed) != 1 || claimed[0].ID != registered.ID || claimed[0].Attempts != 1 {
+		t.Fatalf("claim after a failure = %+v, want event %d again after 1 attempt", claimed, registered.ID)
+	}
+
+	for _, want := range []string{domain.EventUserUpdated, domain.EventUserDeleted} {
+		if err := store.MarkOutboxEventPublished(ctx, claimed[0].ID); err != nil {
+			t.Fatalf("MarkOutboxEventPublished: %v", err)
+		}
+		claimed = claimOwn(t, store, user.ID)
+		if len(claimed) != 1 || claimed[0].Type != want {
+			t.Fatalf("claim after publishing = %+v, want only %s", claimed, want)
+		}
+	}
+}
@@ -7,42 +7,67 @@ import (
 
 	"pet-project/internal/domain"
 	"pet-project/internal/logger"
-	"pet-project/internal/storage"
-
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
+	"pet-project/internal/notify"
 )
 
 var (
 	ErrValidation = errors.New("validation error")
 	ErrConflict   = errors.New("conflict error")
 	ErrNotFound   = errors.New("not found error")
+
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
 
+type Service interface {
+	UserService
+	ProductService
+	OrderService
+	WebhookService
+	AuditService
+	PromoService
+	ReportService
+	UnitOfWork
+}
+
+// UnitOfWork makes several service calls atomic: calls made with the
+// context fn receives share one transaction, which is retried from the
+// start on serialization failures and deadlocks.
+type UnitOfWork interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type UserService interface {
 	CreateUser(ctx context.Context, user domain.User) (int64, error)
 	GetUserByID(ctx context.Context, id int64) (domain.User, error)
+	UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error)
+	DeleteUser(ctx context.Context, id int64) error
+	ListUsers(ctx context.Context, filter domain.UserFilter, cursor string) (domain.UserPage, error)
 }
 
 type service struct {
-	repo   *storage.PostgresStorage
-	logger *logger.Logger
+	repo     Repository
+	notifier notify.Notifier
+	logger   *logger.Logger
 }
 
-func New(repo *storage.PostgresStorage, logger *logger.Logger) *service {
+func New(repo Repository, notifier notify.Notifier, logger *logger.Logger) *service {
 	return &service{
-		repo:   repo,
-		logger: logger,
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
 	}
 }
 
+func (s *service) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.repo.WithinTx(ctx, fn)
+}
+
 func (s *service) CreateUser(ctx context.Context, user domain.User) (int64, error) {
 	s.logger.Debug("Validating user", "first_name", user.FirstName, "last_name", user.LastName)
 
 	id, err := s.repo.CreateUser(ctx, user)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == storage.ErrCodeUniqueViolation {
+		if errors.Is(err, domain.ErrAlreadyExists) {
 			s.logger.Error(nil, "User already exists", "first_name", user.FirstName, "last_name", user.LastName)
 			return 0, fmt.Errorf("%w: user with name %s %s already exissts", ErrConflict, user.FirstName, user.LastName)
 		}
@@ -58,7 +83,7 @@ func (s *service) GetUserByID(ctx context.Context, id int64) (domain.User, error
 	s.logger.Debug("Fetching user", "id", id)
 	user, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, domain.ErrNotFound) {
 			s.logger.Error(nil, "User not found", "id", id)
 			return domain.User{}, fmt.Errorf("%w: user with id %d not found", ErrNotFound, id)
 		}
@@ -69,3 +94,70 @@ func (s *service) GetUserByID(ctx context.Context, id int64) (domain.User, error
 	s.logger.Debug("User fetched succesfully", "id", id)
 	return user, nil
 }
+
+func (s *service) UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error) {
+	s.logger.Debug("Updating user", "id", id, "version", version)
+	user, err := s.repo.UpdateUser(ctx, id, version, update)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "User not found", "id", id)
+			return domain.User{}, fmt.Errorf("%w: user with id %d not found", ErrNotFound, id)
+		}
+		if errors.Is(err, domain.ErrVersionConflict) {
+			s.logger.Error(nil, "User was modified concurrently", "id", id, "version", version)
+			return domain.User{}, fmt.Errorf("%w: user with id %d was modified, reload it and retry", ErrPreconditionFailed, id)
+		}
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			s.logger.Error(nil, "User already exists", "id", id)
+			return domain.User{}, fmt.Errorf("%w: user with this name already exists", ErrConflict)
+		}
+		s.logger.Error(err, "Failed to update user", "id", id)
+		return domain.User{}, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.logger.Info("User updated successfully", "id", id)
+	return user, nil
+}
+
+func (s *service) DeleteUser(ctx context.Context, id int64) error {
+	s.logger.Debug("Deleting user", "id", id)
+	if err := s.repo.DeleteUser(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "User not found", "id", id)
+			return fmt.Errorf("%w: user with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to delete user", "id", id)
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	s.logger.Info("User deleted successfully", "id", id)
+	return nil
+}
+
+func (s *service) ListUsers(ctx context.Context, filter domain.UserFilter, cursor string) (domain.UserPage, error) {
+	s.logger.Debug("Listing users", "search", filter.Search, "sort", filter.SortBy)
+	if cursor != "" {
+		after, err := decodeUserCursor(cursor, filter.SortBy)
+		if err != nil {
+			return domain.UserPage{}, err
+		}
+		filter.After = after
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	users, err := s.repo.ListUsers(ctx, filter)
+	if err != nil {
+		s.logger.Error(err, "Failed to list users")
+		return domain.UserPage{}, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	page := domain.UserPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor = encodeUserCursor(filter.SortBy, page.Users[limit-1])
+	}
+
+	s.logger.Debug("Users listed successfully", "count", len(page.Users))
+	return page, nil
+}
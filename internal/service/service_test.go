@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+	"pet-project/internal/service/mocks"
+)
+
+type fakeNotifier struct {
+	alerts chan domain.LowStockAlert
+}
+
+func (n fakeNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	n.alerts <- alert
+	return nil
+}
+
+func newTestService(repo *mocks.Repository) *service {
+	return New(repo, fakeNotifier{alerts: make(chan domain.LowStockAlert, 10)}, logger.New("test"))
+}
+
+// Repositories wrap the domain errors, so the service must unwrap them.
+func TestRepositoryErrorsMapToServiceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		repo mocks.Repository
+		call func(s *service) error
+		want error
+	}{
+		{
+			name: "missing user",
+			repo: mocks.Repository{UserRepository: mocks.UserRepository{
+				GetUserByIDFunc: func(ctx context.Context, id int64) (domain.User, error) {
+					return domain.User{}, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+				},
+			}},
+			call: func(s *service) error {
+				_, err := s.GetUserByID(context.Background(), 1)
+				return err
+			},
+			want: ErrNotFound,
+		},
+		{
+			name: "duplicate user",
+			repo: mocks.Repository{UserRepository: mocks.UserRepository{
+				CreateUserFunc: func(ctx context.Context, user domain.User) (int64, error) {
+					return 0, fmt.Errorf("user with name Ivan Petrov already exists: %w", domain.ErrAlreadyExists)
+				},
+			}},
+			call: func(s *service) error {
+				_, err := s.CreateUser(context.Background(), domain.User{FirstName: "Ivan", LastName: "Petrov"})
+				return err
+			},
+			want: ErrConflict,
+		},
+		{
+			name: "stale product version",
+			repo: mocks.Repository{ProductRepository: mocks.ProductRepository{
+				UpdateProductFunc: func(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error) {
+					return domain.Product{}, fmt.Errorf("products row 1 is at version 3, not 2: %w", domain.ErrVersionConflict)
+				},
+			}},
+			call: func(s *service) error {
+				_, err := s.UpdateProduct(context.Background(), 1, 2, domain.ProductUpdate{})
+				return err
+			},
+			want: ErrPreconditionFailed,
+		},
+		{
+			name: "stock adjustment below zero",
+			repo: mocks.Repository{ProductRepository: mocks.ProductRepository{
+				AdjustProductStockFunc: func(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error) {
+					return domain.Product{}, fmt.Errorf("product 1 has 2 in stock, cannot apply -5: %w", domain.ErrInsufficientStock)
+				},
+			}},
+			call: func(s *service) error {
+				_, err := s.AdjustProductStock(context.Background(), 1, domain.StockAdjustment{Delta: -5})
+				return err
+			},
+			want: ErrConflict,
+		},
+		{
+			name: "promo code used up",
+			repo: mocks.Repository{OrderRepository: mocks.OrderRepository{
+				CreateOrderFunc: func(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error) {
+					return 0, nil, fmt.Errorf("promo code SALE has been used up: %w", domain.ErrPromoExhausted)
+				},
+			}},
+			call: func(s *service) error {
+				_, err := s.CreateOrder(context.Background(), domain.NewOrder{UserID: 1})
+				return err
+			},
+			want: ErrConflict,
+		},
+		{
+			name: "unexpected failure",
+			repo: mocks.Repository{PromoRepository: mocks.PromoRepository{
+				ListPromoCodesFunc: func(ctx context.Context) ([]domain.PromoCode, error) {
+					return nil, errors.New("connection reset")
+				},
+			}},
+			call: func(s *service) error {
+				_, err := s.ListPromoCodes(context.Background())
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(newTestService(&tt.repo))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, serviceErr := range []error{ErrValidation, ErrConflict, ErrNotFound, ErrPreconditionFailed} {
+				if got := errors.Is(err, serviceErr); got != (serviceErr == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, serviceErr, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateUserPassesUserToRepository(t *testing.T) {
+	var stored domain.User
+	repo := &mocks.Repository{UserRepository: mocks.UserRepository{
+		CreateUserFunc: func(ctx context.Context, user domain.User) (int64, error) {
+			stored = user
+			return 7, nil
+		},
+	}}
+
+	id, err := newTestService(repo).CreateUser(context.Background(), domain.User{FirstName: "Ivan", LastName: "Petrov", Age: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 {
+		t.Errorf("id = %d, want 7", id)
+	}
+	if stored.FirstName != "Ivan" || stored.LastName != "Petrov" || stored.Age != 30 {
+		t.Errorf("repository got %+v", stored)
+	}
+}
+
+func TestImportProductsNotifiesLowStockAfterCommit(t *testing.T) {
+	tx := &fakeTransactor{}
+	repo := &mocks.Repository{
+		ProductRepository: mocks.ProductRepository{
+			ImportProductsFunc: func(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, []domain.LowStockAlert, error) {
+				return domain.ProductImportResult{Rows: 1, Updated: 1}, []domain.LowStockAlert{{ProductID: 3, Quantity: 2, ReorderThreshold: 5}}, nil
+			},
+		},
+		Transactor: tx.mock(),
+	}
+	notifier := fakeNotifier{alerts: make(chan domain.LowStockAlert, 1)}
+	s := New(repo, notifier, logger.New("test"))
+
+	if _, err := s.ImportProducts(context.Background(), nil, domain.ProductImportOptions{Upsert: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.pending) != 1 {
+		t.Fatalf("%d functions run after commit, want the notification", len(tx.pending))
+	}
+
+	tx.commit()
+	select {
+	case alert := <-notifier.alerts:
+		if alert.ProductID != 3 || alert.OrderID != 0 {
+			t.Errorf("alert = %+v", alert)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no alert after commit")
+	}
+}
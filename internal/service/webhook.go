@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"pet-project/internal/domain"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id int64) (domain.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID int64, cursor string, limit int) (domain.WebhookDeliveryPage, error)
+	GetWebhookDelivery(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error)
+}
+
+// CreateWebhook stores the subscription, generating a secret when none is
+// given. The returned subscription is the only place the secret is shown.
+func (s *service) CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	s.logger.Debug("Creating webhook", "url", sub.URL)
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return domain.WebhookSubscription{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		sub.Secret = hex.EncodeToString(secret)
+	}
+
+	sub, err := s.repo.CreateWebhookSubscription(ctx, sub)
+	if err != nil {
+		s.logger.Error(err, "Failed to create webhook", "url", sub.URL)
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	s.logger.Info("Webhook created successfully", "id", sub.ID)
+	return sub, nil
+}
+
+func (s *service) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	s.logger.Debug("Listing webhooks")
+	subs, err := s.repo.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to list webhooks")
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return subs, nil
+}
+
+func (s *service) GetWebhook(ctx context.Context, id int64) (domain.WebhookSubscription, error) {
+	s.logger.Debug("Fetching webhook", "id", id)
+	sub, err := s.repo.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Webhook not found", "id", id)
+			return domain.WebhookSubscription{}, fmt.Errorf("%w: webhook with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to get webhook", "id", id)
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return sub, nil
+}
+
+func (s *service) DeleteWebhook(ctx context.Context, id int64) error {
+	s.logger.Debug("Deleting webhook", "id", id)
+	if err := s.repo.DeleteWebhookSubscription(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Webhook not found", "id", id)
+			return fmt.Errorf("%w: webhook with id %d not found", ErrNotFound, id)
+		}
+		s.logger.Error(err, "Failed to delete webhook", "id", id)
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	s.logger.Info("Webhook deleted successfully", "id", id)
+	return nil
+}
+
+func (s *service) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, cursor string, limit int) (domain.WebhookDeliveryPage, error) {
+	s.logger.Debug("Listing webhook deliveries", "subscription_id", subscriptionID)
+	var before int64
+	if cursor != "" {
+		var err error
+		before, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return domain.WebhookDeliveryPage{}, fmt.Errorf("%w: invalid cursor", ErrValidation)
+		}
+	}
+
+	if _, err := s.GetWebhook(ctx, subscriptionID); err != nil {
+		return domain.WebhookDeliveryPage{}, err
+	}
+
+	deliveries, err := s.repo.ListWebhookDeliveries(ctx, subscriptionID, before, limit+1)
+	if err != nil {
+		s.logger.Error(err, "Failed to list webhook deliveries", "subscription_id", subscriptionID)
+		return domain.WebhookDeliveryPage{}, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	page := domain.WebhookDeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextCursor = strconv.FormatInt(page.Deliveries[limit-1].ID, 10)
+	}
+	return page, nil
+}
+
+func (s *service) GetWebhookDelivery(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error) {
+	s.logger.Debug("Fetching webhook delivery", "subscription_id", subscriptionID, "id", id)
+	delivery, err := s.repo.GetWebhookDelivery(ctx, subscriptionID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Webhook delivery not found", "subscription_id", subscriptionID, "id", id)
+			return domain.WebhookDelivery{}, fmt.Errorf("%w: delivery %d of webhook %d not found", ErrNotFound, id, subscriptionID)
+		}
+		s.logger.Error(err, "Failed to get webhook delivery", "id", id)
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (s *service) RedeliverWebhook(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error) {
+	s.logger.Debug("Redelivering webhook", "subscription_id", subscriptionID, "id", id)
+	delivery, err := s.repo.RedeliverWebhook(ctx, subscriptionID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.logger.Error(nil, "Webhook delivery not found", "subscription_id", subscriptionID, "id", id)
+			return domain.WebhookDelivery{}, fmt.Errorf("%w: delivery %d of webhook %d not found", ErrNotFound, id, subscriptionID)
+		}
+		s.logger.Error(err, "Failed to redeliver webhook", "id", id)
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	s.logger.Info("Webhook queued for redelivery", "id", id)
+	return delivery, nil
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/reqmeta"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var auditTables = map[string]string{
+	domain.AggregateUser:    "users",
+	domain.AggregateProduct: "products",
+	domain.AggregateOrder:   "orders",
+}
+
+// auditColumns are the columns audit images hold, named as in the API. They
+// are listed rather than taken from the whole row so that bookkeeping such
+// as deleted_at, internal ids and any column added later, however
+// sensitive, stay out of the audit log until they are added here.
+var auditColumns = map[string][]string{
+	domain.AggregateUser:    {"first_name", "last_name", "age", "is_married", "version"},
+	domain.AggregateProduct: {"sku", "description", "tags", "quantity", "price", "tax_class", "reorder_threshold", "version"},
+	domain.AggregateOrder:   {"user_id", "status", "cancelled_at", "subtotal", "discount", "net_total", "tax_total", "total_price"},
+}
+
+// auditImage returns the SQL expression of the audit image of the entity's
+// row aliased alias.
+func auditImage(entity, alias string) string {
+	var args []string
+	for _, column := range auditColumns[entity] {
+		args = append(args, fmt.Sprintf("'%s', %s.%s", column, alias, column))
+	}
+	return "jsonb_build_object(" + strings.Join(args, ", ") + ")"
+}
+
+// auditSnapshot locks a row and returns its audit image as a map for
+// recordAudit, or nil if it does not exist.
+func auditSnapshot(ctx context.Context, q querier, entity string, id int64) (map[string]any, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s t WHERE id = $1 FOR UPDATE`, auditImage(entity, "t"), auditTables[entity])
+	var row map[string]any
+	err := q.QueryRow(ctx, query, id).Scan(&row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s %d: %w", entity, id, err)
+	}
+	return row, nil
+}
+
+// recordAudit compares before, taken with auditSnapshot ahead of the change,
+// with the row as it is now and writes the difference with the actor and
+// request ID from ctx. A delete records the whole before image instead,
+// since a soft-deleted row differs from it only in columns the image leaves
+// out. Call it with the transaction that made the change.
+func recordAudit(ctx context.Context, q querier, entity string, id int64, action string, before map[string]any) error {
+	var after map[string]any
+	if action != domain.AuditDelete {
+		var err error
+		if after, err = auditSnapshot(ctx, q, entity, id); err != nil {
+			return err
+		}
+	}
+	beforeJSON, afterJSON, err := auditImages(before, after)
+	if err != nil {
+		return err
+	}
+
+	meta := reqmeta.From(ctx)
+	query := `
+		INSERT INTO audit_log (actor, request_id, entity, entity_id, action, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err := q.Exec(ctx, query, meta.Actor, auditRequestID(meta), entity, id, action, beforeJSON, afterJSON); err != nil {
+		return fmt.Errorf("failed to record audit entry for %s %d: %w", entity, id, err)
+	}
+	return nil
+}
+
+// auditImages encodes the fields that differ between the images, leaving
+// an image nil when none of its fields did.
+func auditImages(before, after map[string]any) ([]byte, []byte, error) {
+	before, after = auditDiff(before, after)
+
+	var beforeJSON, afterJSON []byte
+	var err error
+	if len(before) > 0 {
+		if beforeJSON, err = json.Marshal(before); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode audit before image: %w", err)
+		}
+	}
+	if len(after) > 0 {
+		if afterJSON, err = json.Marshal(after); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode audit after image: %w", err)
+		}
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func auditRequestID(meta reqmeta.Meta) *string {
+	if meta.RequestID == "" {
+		return nil
+	}
+	return &meta.RequestID
+}
+
+// auditDiff keeps only the fields whose values differ between the images.
+func auditDiff(before, after map[string]any) (map[string]any, map[string]any) {
+	changedBefore := make(map[string]any)
+	changedAfter := make(map[string]any)
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			changedAfter[key] = value
+			if ok {
+				changedBefore[key] = old
+			}
+		}
+	}
+	for key, old := range before {
+		if _, ok := after[key]; !ok {
+			changedBefore[key] = old
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+func (s *PostgresStorage) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	s.logger.Info("Listing audit entries", "entity", filter.Entity, "before", filter.Before, "limit", filter.Limit)
+	query := `
+		SELECT id, actor, COALESCE(request_id, ''), entity, entity_id, action, before, after, created_at
+		FROM audit_log
+		WHERE ($1 = '' OR entity = $1)
+			AND ($2::bigint IS NULL OR entity_id = $2)
+			AND ($3::timestamptz IS NULL OR created_at >= $3)
+			AND ($4::timestamptz IS NULL OR created_at < $4)
+			AND ($5::bigint = 0 OR id < $5::bigint)
+		ORDER BY id DESC
+		LIMIT $6
+	`
+	rows, err := s.reader(ctx).Query(ctx, query, filter.Entity, filter.EntityID, filter.From, filter.To, filter.Before, filter.Limit)
+	if err != nil {
+		s.logger.Error(err, "Failed to list audit entries")
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.AuditEntry, error) {
+		var e domain.AuditEntry
+		err := row.Scan(&e.ID, &e.Actor, &e.RequestID, &e.Entity, &e.EntityID, &e.Action, &e.Before, &e.After, &e.CreatedAt)
+		return e, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan audit entries")
+		return nil, fmt.Errorf("failed to scan audit entries: %w", err)
+	}
+
+	s.logger.Info("Audit entries listed", "count", len(entries))
+	return entries, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestAuditImage(t *testing.T) {
+	got := auditImage(domain.AggregateUser, "t")
+	want := "jsonb_build_object('first_name', t.first_name, 'last_name', t.last_name, 'age', t.age, 'is_married', t.is_married, 'version', t.version)"
+	if got != want {
+		t.Errorf("auditImage(user) = %s, want %s", got, want)
+	}
+
+	for entity := range auditTables {
+		image := auditImage(entity, "t")
+		for _, column := range []string{"password", "deleted_at", "promo_code_id", "'id'"} {
+			if strings.Contains(image, column) {
+				t.Errorf("audit image of %s includes %s: %s", entity, column, image)
+			}
+		}
+	}
+}
+
+// auditQuerier serves every snapshot from image and keeps the arguments of
+// the last statement executed.
+type auditQuerier struct {
+	querier
+	image map[string]any
+	args  []any
+}
+
+func (q *auditQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return fakeRow{q.image}
+}
+
+func (q *auditQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.args = args
+	return pgconn.CommandTag{}, nil
+}
+
+func TestRecordAuditOfDelete(t *testing.T) {
+	// A soft delete only sets deleted_at, so the row's image is unchanged.
+	image := map[string]any{"first_name": "Ivan", "last_name": "Petrov", "age": float64(30), "is_married": false, "version": float64(2)}
+	q := &auditQuerier{image: image}
+
+	if err := recordAudit(context.Background(), q, domain.AggregateUser, 1, domain.AuditDelete, image); err != nil {
+		t.Fatal(err)
+	}
+
+	before, after := q.args[5].([]byte), q.args[6].([]byte)
+	var got map[string]any
+	if err := json.Unmarshal(before, &got); err != nil {
+		t.Fatalf("before = %s: %v", before, err)
+	}
+	if !reflect.DeepEqual(got, image) {
+		t.Errorf("before = %v, want the whole image %v", got, image)
+	}
+	if after != nil {
+		t.Errorf("after = %s, want none", after)
+	}
+}
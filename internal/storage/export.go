@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const exportFetchSize = 1000
+
+// ExportOrders passes every line of the orders created in [from, to) to
+// emit, oldest order first, and returns how many it passed. Lines are
+// fetched from a cursor a batch at a time, so memory use does not grow with
+// the range, and the repeatable read transaction makes the export one
+// consistent snapshot however long it takes.
+func (s *PostgresStorage) ExportOrders(ctx context.Context, from, to time.Time, emit func(domain.OrderExportRow) error) (int, error) {
+	s.logger.Info("Exporting orders", "from", from, "to", to)
+	tx, err := s.beginOn(ctx, s.readPool(ctx), pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		DECLARE order_export NO SCROLL CURSOR FOR
+		SELECT o.id, o.user_id, o.created_at, o.status,
+			op.product_id, op.product_version, op.description, op.quantity, op.price, op.discount, op.tax_amount,
+			o.total_price
+		FROM orders o
+		JOIN order_product op ON op.order_id = o.id
+		WHERE o.created_at >= $1 AND o.created_at < $2
+		ORDER BY o.created_at, o.id, op.product_id
+	`
+	if _, err := tx.Exec(ctx, query, from, to); err != nil {
+		s.logger.Error(err, "Failed to open order export cursor")
+		return 0, fmt.Errorf("failed to open order export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM order_export", exportFetchSize)
+	var (
+		row   domain.OrderExportRow
+		count int
+	)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			s.logger.Error(err, "Failed to fetch exported orders")
+			return count, fmt.Errorf("failed to fetch exported orders: %w", err)
+		}
+		fetched := 0
+		_, err = pgx.ForEachRow(rows, []any{
+			&row.OrderID, &row.UserID, &row.CreatedAt, &row.Status,
+			&row.ProductID, &row.ProductVersion, &row.Description, &row.Quantity, &row.Price, &row.Discount, &row.TaxAmount,
+			&row.OrderTotal,
+		}, func() error {
+			fetched++
+			return emit(row)
+		})
+		count += fetched
+		if err != nil {
+			return count, fmt.Errorf("failed to export orders: %w", err)
+		}
+		if fetched < exportFetchSize {
+			break
+		}
+	}
+
+	s.logger.Info("Orders exported", "rows", count)
+	return count, nil
+}
@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/reqmeta"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var importColumns = []string{
+	"line", "sku", "description", "tags", "quantity", "price", "tax_class", "reorder_threshold",
+	"has_quantity", "has_tax_class", "has_reorder_threshold",
+}
+
+// ImportProducts writes an import in one transaction. Rows are copied into
+// a staging table and applied with set-based statements; the stock ledger,
+// product versions, audit log and outbox are written the same way. Rows
+// with an unknown tax class or, unless upserting, a SKU that is already
+// taken are reported and nothing is written. A dry run stops just before
+// the first write. Updated products whose stock fell to their reorder
+// threshold are returned as alerts, like orders return them.
+func (s *PostgresStorage) ImportProducts(ctx context.Context, rows []domain.ProductImportRow, opts domain.ProductImportOptions) (domain.ProductImportResult, []domain.LowStockAlert, error) {
+	s.logger.Info("Importing products", "rows", len(rows), "dry_run", opts.DryRun, "upsert", opts.Upsert)
+	result := domain.ProductImportResult{DryRun: opts.DryRun, Rows: len(rows)}
+
+	products := make(map[int]domain.Product, len(rows))
+	for _, row := range rows {
+		product := row.Product
+		if product.Tags == nil {
+			product.Tags = []string{}
+		}
+		if product.TaxClass == "" {
+			product.TaxClass = s.tax.DefaultClass
+		}
+		if _, err := s.taxRate(product.TaxClass); err != nil {
+			result.Errors = append(result.Errors, domain.ImportRowError{Line: row.Line, SKU: product.SKU, Error: err.Error()})
+		}
+		products[row.Line] = product
+	}
+	if len(result.Errors) > 0 {
+		return result, nil, nil
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		CREATE TEMPORARY TABLE product_import (
+			line                  INT PRIMARY KEY,
+			sku                   TEXT,
+			description           TEXT NOT NULL,
+			tags                  TEXT[] NOT NULL,
+			quantity              INT NOT NULL,
+			price                 NUMERIC(12, 2) NOT NULL,
+			tax_class             TEXT NOT NULL,
+			reorder_threshold     INT,
+			has_quantity          BOOLEAN NOT NULL,
+			has_tax_class         BOOLEAN NOT NULL,
+			has_reorder_threshold BOOLEAN NOT NULL,
+			product_id            BIGINT,
+			old_quantity          INT
+		) ON COMMIT DROP
+	`
+	if _, err := tx.Exec(ctx, query); err != nil {
+		s.logger.Error(err, "Failed to create import staging table")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to create import staging table: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"product_import"}, importColumns, pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+		line := rows[i].Line
+		product := products[line]
+		var sku *string
+		if product.SKU != "" {
+			sku = &product.SKU
+		}
+		return []any{
+			line, sku, product.Description, product.Tags, product.Quantity, product.Price, product.TaxClass, product.ReorderThreshold,
+			rows[i].HasQuantity, rows[i].HasTaxClass, rows[i].HasReorderThreshold,
+		}, nil
+	}))
+	if err != nil {
+		s.logger.Error(err, "Failed to copy import rows")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to copy import rows: %w", err)
+	}
+
+	// Lock the products being replaced, in id order like every other
+	// multi-product write, and keep their audit before images.
+	query = fmt.Sprintf(`
+		SELECT i.line, p.id, p.quantity, %s
+		FROM product_import i
+		JOIN products p ON p.sku = i.sku
+		ORDER BY p.id
+		FOR UPDATE OF p
+	`, auditImage(domain.AggregateProduct, "p"))
+	existing, err := tx.Query(ctx, query)
+	if err != nil {
+		s.logger.Error(err, "Failed to lock existing products")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to lock existing products: %w", err)
+	}
+	before := make(map[int64]map[string]any)
+	var (
+		line, quantity int
+		id             int64
+		snapshot       map[string]any
+	)
+	_, err = pgx.ForEachRow(existing, []any{&line, &id, &quantity, &snapshot}, func() error {
+		before[id] = snapshot
+		if !opts.Upsert {
+			result.Errors = append(result.Errors, domain.ImportRowError{
+				Line:  line,
+				SKU:   products[line].SKU,
+				Error: "a product with this sku already exists, import with upsert to update it",
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan existing products")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to scan existing products: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return result, nil, nil
+	}
+	result.Updated = len(before)
+	result.Created = len(rows) - len(before)
+	if opts.DryRun {
+		s.logger.Info("Product import checked", "created", result.Created, "updated", result.Updated)
+		return result, nil, nil
+	}
+
+	// Matched rows take the existing product's id, and its values of the
+	// optional fields the file left out; the rest are given new ids up front
+	// so every later statement can join on product_id.
+	statements := []struct {
+		query, failure string
+	}{
+		{`
+			UPDATE product_import i
+			SET product_id = p.id,
+				old_quantity = p.quantity,
+				quantity = CASE WHEN i.has_quantity THEN i.quantity ELSE p.quantity END,
+				tax_class = CASE WHEN i.has_tax_class THEN i.tax_class ELSE p.tax_class END,
+				reorder_threshold = CASE WHEN i.has_reorder_threshold THEN i.reorder_threshold ELSE p.reorder_threshold END
+			FROM products p
+			WHERE p.sku = i.sku
+		`, "match existing products"},
+		{`
+			UPDATE product_import i
+			SET product_id = n.id
+			FROM (
+				SELECT line, nextval(pg_get_serial_sequence('products', 'id')) AS id
+				FROM product_import
+				WHERE product_id IS NULL
+				ORDER BY line
+			) n
+			WHERE n.line = i.line
+		`, "allocate product ids"},
+		{`
+			UPDATE products p
+			SET description = i.description,
+				tags = i.tags,
+				quantity = i.quantity,
+				price = i.price,
+				tax_class = i.tax_class,
+				reorder_threshold = i.reorder_threshold,
+				version = p.version + 1
+			FROM product_import i
+			WHERE i.old_quantity IS NOT NULL AND p.id = i.product_id
+		`, "update products"},
+		{`
+			INSERT INTO products (id, sku, description, tags, quantity, price, tax_class, reorder_threshold)
+			SELECT product_id, sku, description, tags, quantity, price, tax_class, reorder_threshold
+			FROM product_import
+			WHERE old_quantity IS NULL
+			ORDER BY line
+		`, "insert products"},
+		{`
+			INSERT INTO stock_movements (product_id, kind, delta, quantity_after, reason)
+			SELECT product_id,
+				CASE WHEN old_quantity IS NULL THEN 'initial' ELSE 'correction' END,
+				quantity - COALESCE(old_quantity, 0),
+				quantity,
+				'product import'
+			FROM product_import
+			WHERE quantity <> COALESCE(old_quantity, 0)
+			ORDER BY line
+		`, "record stock movements"},
+		{`
+			INSERT INTO product_versions (product_id, version, description, tags, price, tax_class, reorder_threshold)
+			SELECT p.id, p.version, p.description, p.tags, p.price, p.tax_class, p.reorder_threshold
+			FROM products p
+			JOIN product_import i ON i.product_id = p.id
+		`, "record product versions"},
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement.query); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
+				return domain.ProductImportResult{}, nil, fmt.Errorf("an imported sku was created meanwhile: %w", domain.ErrAlreadyExists)
+			}
+			s.logger.Error(err, "Failed to "+statement.failure)
+			return domain.ProductImportResult{}, nil, fmt.Errorf("failed to %s: %w", statement.failure, err)
+		}
+	}
+
+	alerts, err := importAlerts(ctx, tx)
+	if err != nil {
+		s.logger.Error(err, "Failed to check reorder thresholds")
+		return domain.ProductImportResult{}, nil, err
+	}
+
+	if err := s.recordImportChanges(ctx, tx, products, before); err != nil {
+		s.logger.Error(err, "Failed to record imported changes")
+		return domain.ProductImportResult{}, nil, err
+	}
+
+	// ON COMMIT DROP does not fire when the import is part of a unit of
+	// work and tx is only a savepoint.
+	if _, err := tx.Exec(ctx, `DROP TABLE product_import`); err != nil {
+		s.logger.Error(err, "Failed to drop import staging table")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to drop import staging table: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return domain.ProductImportResult{}, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Products imported", "created", result.Created, "updated", result.Updated, "low_stock", len(alerts))
+	return result, alerts, nil
+}
+
+// importAlerts finds the updated products whose stock the import took from
+// above their reorder threshold to at or below it, the same crossing an
+// order raises an alert for.
+func importAlerts(ctx context.Context, tx pgx.Tx) ([]domain.LowStockAlert, error) {
+	query := `
+		SELECT product_id, description, quantity, reorder_threshold, now()
+		FROM product_import
+		WHERE old_quantity IS NOT NULL
+			AND quantity <= reorder_threshold
+			AND old_quantity > reorder_threshold
+		ORDER BY line
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reorder thresholds: %w", err)
+	}
+	alerts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.LowStockAlert, error) {
+		var a domain.LowStockAlert
+		err := row.Scan(&a.ProductID, &a.Description, &a.Quantity, &a.ReorderThreshold, &a.OccurredAt)
+		return a, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan low stock products: %w", err)
+	}
+	return alerts, nil
+}
+
+// recordImportChanges writes an audit entry and an outbox event for every
+// imported product, as recordAudit and enqueueEvent would one at a time.
+func (s *PostgresStorage) recordImportChanges(ctx context.Context, tx pgx.Tx, products map[int]domain.Product, before map[int64]map[string]any) error {
+	query := fmt.Sprintf(`
+		SELECT i.line, p.id, %s
+		FROM product_import i
+		JOIN products p ON p.id = i.product_id
+		ORDER BY i.line
+	`, auditImage(domain.AggregateProduct, "p"))
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to read imported products: %w", err)
+	}
+
+	meta := reqmeta.From(ctx)
+	requestID := auditRequestID(meta)
+	var audits, events [][]any
+	var (
+		line  int
+		id    int64
+		after map[string]any
+	)
+	_, err = pgx.ForEachRow(rows, []any{&line, &id, &after}, func() error {
+		action, eventType := domain.AuditCreate, domain.EventProductCreated
+		previous, updated := before[id]
+		if updated {
+			action, eventType = domain.AuditUpdate, domain.EventProductUpdated
+		}
+		beforeJSON, afterJSON, err := auditImages(previous, after)
+		if err != nil {
+			return err
+		}
+		audits = append(audits, []any{meta.Actor, requestID, domain.AggregateProduct, id, action, beforeJSON, afterJSON})
+
+		product := products[line]
+		product.ID = id
+		payload, err := json.Marshal(productEventPayload(product))
+		if err != nil {
+			return fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+		}
+		events = append(events, []any{domain.AggregateProduct, id, eventType, payload})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read imported products: %w", err)
+	}
+
+	columns := []string{"actor", "request_id", "entity", "entity_id", "action", "before", "after"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"audit_log"}, columns, pgx.CopyFromRows(audits)); err != nil {
+		return fmt.Errorf("failed to record audit entries: %w", err)
+	}
+	columns = []string{"aggregate_type", "aggregate_id", "event_type", "payload"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"outbox_events"}, columns, pgx.CopyFromRows(events)); err != nil {
+		return fmt.Errorf("failed to enqueue product events: %w", err)
+	}
+	return nil
+}
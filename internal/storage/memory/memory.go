@@ -0,0 +1,429 @@
+// Package memory keeps users, products and orders in process. It implements
+// the operations the repository conformance suite covers with the same
+// outcomes as the Postgres storage, so the suite and the service can run
+// without a database. It has no promo codes: an order naming one is
+// rejected as not applicable.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+)
+
+type Storage struct {
+	mu   sync.Mutex
+	tax  config.Tax
+	data state
+}
+
+// state is everything the storage holds. Values in its maps are replaced,
+// never changed in place, so copying the maps is enough to snapshot it.
+type state struct {
+	users    map[int64]user
+	products map[int64]product
+	orders   map[int64]domain.Order
+	lastID   int64
+}
+
+type user struct {
+	domain.User
+	deleted bool
+}
+
+// product is a product with the version of its last edit, the one order
+// lines refer to; stock changes bump Version but make no new edit.
+type product struct {
+	domain.Product
+	editVersion int64
+}
+
+func New(tax config.Tax) *Storage {
+	return &Storage{
+		tax: tax,
+		data: state{
+			users:    map[int64]user{},
+			products: map[int64]product{},
+			orders:   map[int64]domain.Order{},
+		},
+	}
+}
+
+func (d state) clone() state {
+	d.users = maps.Clone(d.users)
+	d.products = maps.Clone(d.products)
+	d.orders = maps.Clone(d.orders)
+	return d
+}
+
+func (d *state) nextID() int64 {
+	d.lastID++
+	return d.lastID
+}
+
+type unitOfWork struct {
+	storage     *Storage
+	afterCommit []func()
+}
+
+type unitOfWorkKey struct{}
+
+func (s *Storage) unitOfWork(ctx context.Context) *unitOfWork {
+	uow, _ := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if uow == nil || uow.storage != s {
+		return nil
+	}
+	return uow
+}
+
+// lock takes the storage lock unless ctx belongs to a unit of work, which
+// holds it already.
+func (s *Storage) lock(ctx context.Context) func() {
+	if s.unitOfWork(ctx) != nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// WithinTx runs fn holding the storage lock, so units of work are
+// serializable, and puts back what fn changed when it fails. A WithinTx
+// inside another joins the outer one.
+func (s *Storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.unitOfWork(ctx) != nil {
+		return fn(ctx)
+	}
+
+	uow := &unitOfWork{storage: s}
+	err := func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		snapshot := s.data.clone()
+		err := fn(context.WithValue(ctx, unitOfWorkKey{}, uow))
+		if err != nil {
+			s.data = snapshot
+		}
+		return err
+	}()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range uow.afterCommit {
+		f()
+	}
+	return nil
+}
+
+func (s *Storage) AfterCommit(ctx context.Context, f func()) {
+	if uow := s.unitOfWork(ctx); uow != nil {
+		uow.afterCommit = append(uow.afterCommit, f)
+		return
+	}
+	f()
+}
+
+func (s *Storage) CreateUser(ctx context.Context, u domain.User) (int64, error) {
+	defer s.lock(ctx)()
+	if s.nameTaken(u.FirstName, u.LastName, 0) {
+		return 0, fmt.Errorf("user with name %s %s already exists: %w", u.FirstName, u.LastName, domain.ErrAlreadyExists)
+	}
+
+	u.ID = s.data.nextID()
+	u.FullName = u.FirstName + " " + u.LastName
+	u.Version = 1
+	s.data.users[u.ID] = user{User: u}
+	return u.ID, nil
+}
+
+// nameTaken reports whether a user other than id has the name.
+func (s *Storage) nameTaken(firstName, lastName string, id int64) bool {
+	for _, u := range s.data.users {
+		if !u.deleted && u.ID != id && u.FirstName == firstName && u.LastName == lastName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) GetUserByID(ctx context.Context, id int64) (domain.User, error) {
+	defer s.lock(ctx)()
+	u, ok := s.data.users[id]
+	if !ok || u.deleted {
+		return domain.User{}, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	return withoutPassword(u.User), nil
+}
+
+// withoutPassword returns u as the Postgres storage reads users back.
+func withoutPassword(u domain.User) domain.User {
+	u.Password = ""
+	return u
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error) {
+	defer s.lock(ctx)()
+	u, ok := s.data.users[id]
+	if !ok || u.deleted {
+		return domain.User{}, fmt.Errorf("users row %d not found: %w", id, domain.ErrNotFound)
+	}
+	if u.Version != version {
+		return domain.User{}, fmt.Errorf("users row %d is at version %d, not %d: %w", id, u.Version, version, domain.ErrVersionConflict)
+	}
+
+	if update.FirstName != nil {
+		u.FirstName = *update.FirstName
+	}
+	if update.LastName != nil {
+		u.LastName = *update.LastName
+	}
+	if update.IsMarried != nil {
+		u.IsMarried = *update.IsMarried
+	}
+	if s.nameTaken(u.FirstName, u.LastName, id) {
+		return domain.User{}, fmt.Errorf("user with this name already exists: %w", domain.ErrAlreadyExists)
+	}
+	u.FullName = u.FirstName + " " + u.LastName
+	u.Version++
+	s.data.users[id] = u
+	return withoutPassword(u.User), nil
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, id int64) error {
+	defer s.lock(ctx)()
+	u, ok := s.data.users[id]
+	if !ok || u.deleted {
+		return fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	u.deleted = true
+	s.data.users[id] = u
+	return nil
+}
+
+func (s *Storage) taxRate(class string) (float64, error) {
+	rate, ok := s.tax.Classes[class]
+	if !ok {
+		return 0, fmt.Errorf("tax class %q: %w", class, domain.ErrUnknownTaxClass)
+	}
+	return rate, nil
+}
+
+func (s *Storage) CreateProduct(ctx context.Context, p domain.Product) (int64, error) {
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	if p.TaxClass == "" {
+		p.TaxClass = s.tax.DefaultClass
+	}
+	if _, err := s.taxRate(p.TaxClass); err != nil {
+		return 0, err
+	}
+
+	defer s.lock(ctx)()
+	if p.SKU != "" {
+		for _, other := range s.data.products {
+			if other.SKU == p.SKU {
+				return 0, fmt.Errorf("product with sku %s already exists: %w", p.SKU, domain.ErrAlreadyExists)
+			}
+		}
+	}
+
+	p.ID = s.data.nextID()
+	p.Version = 1
+	s.data.products[p.ID] = product{Product: p, editVersion: p.Version}
+	return p.ID, nil
+}
+
+func (s *Storage) GetProductByID(ctx context.Context, id int64) (domain.Product, error) {
+	defer s.lock(ctx)()
+	p, ok := s.data.products[id]
+	if !ok {
+		return domain.Product{}, fmt.Errorf("product not found: %w", domain.ErrNotFound)
+	}
+	return p.Product, nil
+}
+
+func (s *Storage) UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error) {
+	if update.TaxClass != nil {
+		if _, err := s.taxRate(*update.TaxClass); err != nil {
+			return domain.Product{}, err
+		}
+	}
+
+	defer s.lock(ctx)()
+	p, ok := s.data.products[id]
+	if !ok {
+		return domain.Product{}, fmt.Errorf("products row %d not found: %w", id, domain.ErrNotFound)
+	}
+	if p.Version != version {
+		return domain.Product{}, fmt.Errorf("products row %d is at version %d, not %d: %w", id, p.Version, version, domain.ErrVersionConflict)
+	}
+
+	if update.Description != nil {
+		p.Description = *update.Description
+	}
+	if update.Tags != nil {
+		p.Tags = *update.Tags
+	}
+	if update.Price != nil {
+		p.Price = *update.Price
+	}
+	if update.TaxClass != nil {
+		p.TaxClass = *update.TaxClass
+	}
+	if update.ReorderThreshold != nil {
+		p.ReorderThreshold = update.ReorderThreshold
+	}
+	p.Version++
+	p.editVersion = p.Version
+	s.data.products[id] = p
+	return p.Product, nil
+}
+
+func (s *Storage) AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error) {
+	defer s.lock(ctx)()
+	p, ok := s.data.products[id]
+	if !ok {
+		return domain.Product{}, fmt.Errorf("product not found: %w", domain.ErrNotFound)
+	}
+	if p.Quantity+adjustment.Delta < 0 {
+		return domain.Product{}, fmt.Errorf("product %d has %d in stock, cannot apply %d: %w", id, p.Quantity, adjustment.Delta, domain.ErrInsufficientStock)
+	}
+
+	p.Quantity += adjustment.Delta
+	p.Version++
+	s.data.products[id] = p
+	return p.Product, nil
+}
+
+// CreateOrder places the order and reports every product whose stock the
+// order took from above its reorder threshold to at or below it. Every
+// check is made before anything changes, so a failed order leaves no trace.
+func (s *Storage) CreateOrder(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error) {
+	defer s.lock(ctx)()
+	if u, ok := s.data.users[order.UserID]; !ok || u.deleted {
+		return 0, nil, fmt.Errorf("user with id %d not found: %w", order.UserID, domain.ErrNotFound)
+	}
+	if order.PromoCode != "" {
+		return 0, nil, fmt.Errorf("promo code %s: %w", order.PromoCode, domain.ErrPromoNotApplicable)
+	}
+
+	items := slices.Clone(order.Items)
+	slices.SortFunc(items, func(a, b domain.OrderItem) int {
+		return cmp.Compare(a.ProductID, b.ProductID)
+	})
+
+	created := domain.Order{
+		UserID:    order.UserID,
+		Status:    domain.OrderStatusCreated,
+		CreatedAt: time.Now(),
+	}
+	for _, item := range items {
+		p, ok := s.data.products[item.ProductID]
+		if !ok {
+			return 0, nil, fmt.Errorf("product with id %d not found: %w", item.ProductID, domain.ErrNotFound)
+		}
+		if p.Quantity < item.Quantity {
+			return 0, nil, fmt.Errorf("not enough quantity for product %d: available %d, requested %d: %w", item.ProductID, p.Quantity, item.Quantity, domain.ErrInsufficientStock)
+		}
+		rate, err := s.taxRate(p.TaxClass)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		amount := p.Price * float64(item.Quantity)
+		line := domain.OrderProduct{
+			ProductID:      item.ProductID,
+			ProductVersion: p.editVersion,
+			Description:    p.Description,
+			Tags:           p.Tags,
+			Quantity:       item.Quantity,
+			Price:          p.Price,
+			TaxClass:       p.TaxClass,
+			TaxRate:        rate,
+		}
+		if s.tax.PricesIncludeTax {
+			line.TaxAmount = roundMoney(amount * rate / (1 + rate))
+			created.NetTotal += amount - line.TaxAmount
+		} else {
+			line.TaxAmount = roundMoney(amount * rate)
+			created.NetTotal += amount
+		}
+		created.Subtotal += amount
+		created.TaxTotal += line.TaxAmount
+		created.OrderProduct = append(created.OrderProduct, line)
+	}
+	created.Subtotal = roundMoney(created.Subtotal)
+	created.NetTotal = roundMoney(created.NetTotal)
+	created.TaxTotal = roundMoney(created.TaxTotal)
+	created.TotalPrice = roundMoney(created.NetTotal + created.TaxTotal)
+
+	created.ID = s.data.nextID()
+	var alerts []domain.LowStockAlert
+	for i := range created.OrderProduct {
+		line := &created.OrderProduct[i]
+		line.OrderID = created.ID
+
+		p := s.data.products[line.ProductID]
+		p.Quantity -= line.Quantity
+		p.Version++
+		s.data.products[line.ProductID] = p
+
+		if threshold := p.ReorderThreshold; threshold != nil && p.Quantity <= *threshold && p.Quantity+line.Quantity > *threshold {
+			alerts = append(alerts, domain.LowStockAlert{
+				ProductID:        p.ID,
+				Description:      p.Description,
+				Quantity:         p.Quantity,
+				ReorderThreshold: *threshold,
+				OrderID:          created.ID,
+				OccurredAt:       created.CreatedAt,
+			})
+		}
+	}
+	s.data.orders[created.ID] = created
+	return created.ID, alerts, nil
+}
+
+func (s *Storage) GetOrderByID(ctx context.Context, id int64) (domain.Order, error) {
+	defer s.lock(ctx)()
+	order, ok := s.data.orders[id]
+	if !ok {
+		return domain.Order{}, fmt.Errorf("order not found: %w", domain.ErrNotFound)
+	}
+	return order, nil
+}
+
+func (s *Storage) CancelOrder(ctx context.Context, id int64) error {
+	defer s.lock(ctx)()
+	order, ok := s.data.orders[id]
+	if !ok {
+		return fmt.Errorf("order not found: %w", domain.ErrNotFound)
+	}
+	if order.Status == domain.OrderStatusCancelled {
+		return fmt.Errorf("order %d: %w", id, domain.ErrOrderCancelled)
+	}
+
+	for _, line := range order.OrderProduct {
+		p := s.data.products[line.ProductID]
+		p.Quantity += line.Quantity
+		p.Version++
+		s.data.products[line.ProductID] = p
+	}
+	cancelledAt := time.Now()
+	order.Status = domain.OrderStatusCancelled
+	order.CancelledAt = &cancelledAt
+	s.data.orders[id] = order
+	return nil
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,15 @@
+package memory
+
+import (
+	"testing"
+
+	"pet-project/internal/config"
+	"pet-project/internal/service/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	tax := config.Tax{DefaultClass: repotest.TaxClass, Classes: map[string]float64{repotest.TaxClass: 0.2}}
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return New(tax)
+	})
+}
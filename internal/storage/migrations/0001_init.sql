@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS users (
+    id         BIGSERIAL PRIMARY KEY,
+    first_name TEXT NOT NULL,
+    last_name  TEXT NOT NULL,
+    full_name  TEXT GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED,
+    age        INT NOT NULL CHECK (age >= 18),
+    is_married BOOLEAN NOT NULL DEFAULT FALSE,
+    password   TEXT NOT NULL,
+    CONSTRAINT users_first_name_last_name_key UNIQUE (first_name, last_name)
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id          BIGSERIAL PRIMARY KEY,
+    description TEXT NOT NULL,
+    tags        TEXT[] NOT NULL DEFAULT '{}',
+    quantity    INT NOT NULL CHECK (quantity >= 0),
+    price       NUMERIC(12, 2) NOT NULL CHECK (price >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users (id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    total_price NUMERIC(12, 2) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS order_product (
+    order_id   BIGINT NOT NULL REFERENCES orders (id),
+    product_id BIGINT NOT NULL REFERENCES products (id),
+    quantity   INT NOT NULL CHECK (quantity > 0),
+    price      NUMERIC(12, 2) NOT NULL,
+    PRIMARY KEY (order_id, product_id)
+);
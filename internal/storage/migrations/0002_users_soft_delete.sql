@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_first_name_last_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_first_name_last_name_active_key
+    ON users (first_name, last_name)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS users_full_name_trgm_idx
+    ON users USING gin (full_name gin_trgm_ops)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS users_active_full_name_idx
+    ON users (full_name, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS users_active_age_idx
+    ON users (age, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS users_active_is_married_age_idx
+    ON users (is_married, age, id)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'created'
+    CHECK (status IN ('created', 'cancelled'));
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
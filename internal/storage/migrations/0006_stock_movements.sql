@@ -0,0 +1,28 @@
+CREATE TABLE stock_movements (
+    id             BIGSERIAL PRIMARY KEY,
+    product_id     BIGINT NOT NULL REFERENCES products (id),
+    kind           TEXT NOT NULL CHECK (kind IN ('initial', 'order', 'cancellation', 'restock', 'correction')),
+    delta          INT NOT NULL CHECK (delta <> 0),
+    quantity_after INT NOT NULL CHECK (quantity_after >= 0),
+    reason         TEXT NOT NULL DEFAULT '',
+    order_id       BIGINT REFERENCES orders (id),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX stock_movements_product_id_idx ON stock_movements (product_id, id);
+
+CREATE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Existing stock becomes the opening balance so the ledger reconciles.
+INSERT INTO stock_movements (product_id, kind, delta, quantity_after, reason)
+SELECT id, 'initial', quantity, quantity, 'opening balance'
+FROM products
+WHERE quantity <> 0;
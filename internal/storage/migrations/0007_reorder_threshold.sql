@@ -0,0 +1,5 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_threshold INT CHECK (reorder_threshold >= 0);
+
+CREATE INDEX IF NOT EXISTS products_low_stock_idx
+    ON products (id)
+    WHERE quantity <= reorder_threshold;
@@ -0,0 +1,16 @@
+CREATE TABLE outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    aggregate_type  TEXT NOT NULL,
+    aggregate_id    BIGINT NOT NULL,
+    event_type      TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at    TIMESTAMPTZ,
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT
+);
+
+CREATE INDEX outbox_events_pending_idx
+    ON outbox_events (aggregate_type, aggregate_id, id)
+    WHERE published_at IS NULL;
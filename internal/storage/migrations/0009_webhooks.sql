@@ -0,0 +1,42 @@
+CREATE TABLE webhook_subscriptions (
+    id          BIGSERIAL PRIMARY KEY,
+    url         TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret      TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_subscriptions_event_types_idx
+    ON webhook_subscriptions USING gin (event_types)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions (id),
+    event_id        BIGINT NOT NULL REFERENCES outbox_events (id),
+    event_type      TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts        INT NOT NULL DEFAULT 0,
+    response_code   INT,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_attempt_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_pending_idx
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE TABLE webhook_delivery_attempts (
+    id            BIGSERIAL PRIMARY KEY,
+    delivery_id   BIGINT NOT NULL REFERENCES webhook_deliveries (id),
+    attempted_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    response_code INT,
+    error         TEXT,
+    duration_ms   INT NOT NULL
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id, id);
@@ -0,0 +1,30 @@
+CREATE TABLE promo_codes (
+    id                BIGSERIAL PRIMARY KEY,
+    code              TEXT NOT NULL,
+    kind              TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value             NUMERIC(12, 2) NOT NULL CHECK (value > 0),
+    min_order_value   NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (min_order_value >= 0),
+    valid_from        TIMESTAMPTZ,
+    valid_until       TIMESTAMPTZ,
+    max_uses          INT CHECK (max_uses > 0),
+    max_uses_per_user INT CHECK (max_uses_per_user > 0),
+    product_ids       BIGINT[] NOT NULL DEFAULT '{}',
+    tags              TEXT[] NOT NULL DEFAULT '{}',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (kind <> 'percent' OR value <= 100),
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_from < valid_until)
+);
+
+CREATE UNIQUE INDEX promo_codes_code_key ON promo_codes (upper(code));
+
+-- total_price stays what the customer pays; subtotal and discount explain it.
+ALTER TABLE orders
+    ADD COLUMN subtotal      NUMERIC(12, 2),
+    ADD COLUMN discount      NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0),
+    ADD COLUMN promo_code_id BIGINT REFERENCES promo_codes (id);
+
+UPDATE orders SET subtotal = total_price;
+
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+
+CREATE INDEX orders_promo_code_id_idx ON orders (promo_code_id, user_id) WHERE promo_code_id IS NOT NULL;
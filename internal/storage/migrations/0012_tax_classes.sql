@@ -0,0 +1,16 @@
+ALTER TABLE products ADD COLUMN tax_class TEXT NOT NULL DEFAULT 'standard';
+
+-- Lines and orders placed before tax was tracked keep an empty class and no tax.
+ALTER TABLE order_product
+    ADD COLUMN tax_class  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN tax_rate   NUMERIC(6, 4) NOT NULL DEFAULT 0,
+    ADD COLUMN discount   NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+    ADD COLUMN net_total NUMERIC(12, 2),
+    ADD COLUMN tax_total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+UPDATE orders SET net_total = total_price;
+
+ALTER TABLE orders ALTER COLUMN net_total SET NOT NULL;
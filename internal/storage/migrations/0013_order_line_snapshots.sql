@@ -0,0 +1,16 @@
+ALTER TABLE order_product
+    ADD COLUMN description TEXT,
+    ADD COLUMN tags        TEXT[];
+
+-- Earlier lines never captured the product, so the current one is the best
+-- record there is of what was sold.
+UPDATE order_product op
+SET description = p.description,
+    tags = p.tags
+FROM products p
+WHERE p.id = op.product_id;
+
+ALTER TABLE order_product
+    ALTER COLUMN description SET NOT NULL,
+    ALTER COLUMN tags SET NOT NULL,
+    ALTER COLUMN tags SET DEFAULT '{}';
@@ -0,0 +1,46 @@
+-- Every edit of a product's attributes adds a row here. Versions are numbered
+-- by products.version at the time, so stock changes, which bump the version
+-- without editing the product, leave gaps.
+CREATE TABLE product_versions (
+    product_id        BIGINT NOT NULL REFERENCES products (id),
+    version           BIGINT NOT NULL,
+    description       TEXT NOT NULL,
+    tags              TEXT[] NOT NULL,
+    price             NUMERIC(12, 2) NOT NULL,
+    tax_class         TEXT NOT NULL,
+    reorder_threshold INT,
+    valid_from        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, version)
+);
+
+CREATE FUNCTION product_versions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'product_versions is immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_versions_immutable
+    BEFORE UPDATE OR DELETE ON product_versions
+    FOR EACH ROW EXECUTE FUNCTION product_versions_immutable();
+
+-- Products have no creation time, so the first version of an existing one
+-- starts at the earliest trace it left: its opening stock or first order.
+INSERT INTO product_versions (product_id, version, description, tags, price, tax_class, reorder_threshold, valid_from)
+SELECT p.id, p.version, p.description, p.tags, p.price, p.tax_class, p.reorder_threshold,
+    LEAST(
+        now(),
+        (SELECT min(m.created_at) FROM stock_movements m WHERE m.product_id = p.id),
+        (SELECT min(o.created_at) FROM orders o JOIN order_product op ON op.order_id = o.id WHERE op.product_id = p.id)
+    )
+FROM products p;
+
+ALTER TABLE order_product ADD COLUMN product_version BIGINT;
+
+UPDATE order_product op
+SET product_version = p.version
+FROM products p
+WHERE p.id = op.product_id;
+
+ALTER TABLE order_product
+    ALTER COLUMN product_version SET NOT NULL,
+    ADD FOREIGN KEY (product_id, product_version) REFERENCES product_versions (product_id, version);
@@ -0,0 +1,5 @@
+-- External stock keeping unit, set by catalog imports to match rows to
+-- products. Products created one at a time may have none.
+ALTER TABLE products ADD COLUMN sku TEXT;
+
+CREATE UNIQUE INDEX products_sku_key ON products (sku);
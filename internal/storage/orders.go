@@ -0,0 +1,362 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CreateOrder places the order and reports every product whose stock the
+// order took from above its reorder threshold to at or below it.
+func (s *PostgresStorage) CreateOrder(ctx context.Context, order domain.NewOrder) (int64, []domain.LowStockAlert, error) {
+	s.logger.Info("Creating order", "user_id", order.UserID, "items", len(order.Items))
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return 0, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT id
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR SHARE
+	`
+	var userID int64
+	err = tx.QueryRow(ctx, query, order.UserID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil, fmt.Errorf("user with id %d not found: %w", order.UserID, domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to check user", "user_id", order.UserID)
+		return 0, nil, fmt.Errorf("failed to check user %d: %w", order.UserID, err)
+	}
+
+	// Lock products in a fixed order so concurrent orders cannot deadlock.
+	items := slices.Clone(order.Items)
+	slices.SortFunc(items, func(a, b domain.OrderItem) int {
+		return cmp.Compare(a.ProductID, b.ProductID)
+	})
+
+	lines := make([]domain.OrderProduct, len(items))
+	promoLines := make([]promoLine, len(items))
+	var subtotal float64
+	for i, item := range items {
+		var availableQty int
+		query := `
+			SELECT quantity, description, tags, price, tax_class
+			FROM products
+			WHERE id = $1
+			FOR UPDATE
+		`
+		line := &lines[i]
+		err := tx.QueryRow(ctx, query, item.ProductID).Scan(&availableQty, &line.Description, &line.Tags, &line.Price, &line.TaxClass)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, fmt.Errorf("product with id %d not found: %w", item.ProductID, domain.ErrNotFound)
+		}
+		if err != nil {
+			s.logger.Error(err, "Failed to check product", "product_id", item.ProductID)
+			return 0, nil, fmt.Errorf("failed to check product %d: %w", item.ProductID, err)
+		}
+		if availableQty < item.Quantity {
+			return 0, nil, fmt.Errorf("not enough quantity for product %d: available %d, requested %d: %w", item.ProductID, availableQty, item.Quantity, domain.ErrInsufficientStock)
+		}
+		if line.TaxRate, err = s.taxRate(line.TaxClass); err != nil {
+			s.logger.Error(err, "Product has an unconfigured tax class", "product_id", item.ProductID)
+			return 0, nil, err
+		}
+		line.ProductID = item.ProductID
+		line.Quantity = item.Quantity
+		promoLines[i].productID = item.ProductID
+		promoLines[i].tags = line.Tags
+		promoLines[i].amount = line.Price * float64(item.Quantity)
+		subtotal += promoLines[i].amount
+	}
+	subtotal = roundMoney(subtotal)
+	createdAt := time.Now()
+
+	var (
+		promoCodeID *int64
+		discount    float64
+	)
+	eligible := make([]bool, len(lines))
+	if order.PromoCode != "" {
+		promo, amount, err := redeemPromoCode(ctx, tx, order.PromoCode, order.UserID, promoLines, subtotal, createdAt)
+		if err != nil {
+			return 0, nil, err
+		}
+		promoCodeID, discount = &promo.ID, amount
+		for i, line := range promoLines {
+			eligible[i] = promoApplies(promo, line)
+		}
+	}
+	netTotal, taxTotal := s.priceLines(lines, eligible, discount)
+	totalPrice := roundMoney(netTotal + taxTotal)
+
+	var orderID int64
+	query = `
+		INSERT INTO orders (user_id, created_at, subtotal, discount, promo_code_id, net_total, tax_total, total_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err = tx.QueryRow(ctx, query, order.UserID, createdAt, subtotal, discount, promoCodeID, netTotal, taxTotal, totalPrice).Scan(&orderID)
+	if err != nil {
+		s.logger.Error(err, "Failed to create order", "user_id", order.UserID)
+		return 0, nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	var alerts []domain.LowStockAlert
+	for i, item := range items {
+		line := &lines[i]
+		line.OrderID = orderID
+		// The product is locked, so its latest version is the one being sold.
+		query := `
+			INSERT INTO order_product (order_id, product_id, product_version, description, tags, quantity, price, discount, tax_class, tax_rate, tax_amount)
+			VALUES ($1, $2, (SELECT max(version) FROM product_versions WHERE product_id = $2), $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING product_version
+		`
+		err = tx.QueryRow(ctx, query, orderID, item.ProductID, line.Description, line.Tags, item.Quantity, line.Price, line.Discount, line.TaxClass, line.TaxRate, line.TaxAmount).Scan(&line.ProductVersion)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
+				return 0, nil, fmt.Errorf("product %d already exists in order %d: %w", item.ProductID, orderID, err)
+			}
+			s.logger.Error(err, "Failed to add product to order", "product_id", item.ProductID)
+			return 0, nil, fmt.Errorf("failed to add product %d to order: %w", item.ProductID, err)
+		}
+
+		var (
+			quantityAfter int
+			threshold     *int
+			description   string
+		)
+		query = `
+			UPDATE products
+			SET quantity = quantity - $1, version = version + 1
+			WHERE id = $2
+			RETURNING quantity, reorder_threshold, description
+		`
+		err = tx.QueryRow(ctx, query, item.Quantity, item.ProductID).Scan(&quantityAfter, &threshold, &description)
+		if err != nil {
+			s.logger.Error(err, "Failed to update product quantity", "product_id", item.ProductID)
+			return 0, nil, fmt.Errorf("failed to update quantity for product %d: %w", item.ProductID, err)
+		}
+
+		err = recordMovement(ctx, tx, domain.StockMovement{
+			ProductID:     item.ProductID,
+			Kind:          domain.MovementOrder,
+			Delta:         -item.Quantity,
+			QuantityAfter: quantityAfter,
+			Reason:        fmt.Sprintf("order %d", orderID),
+			OrderID:       &orderID,
+		})
+		if err != nil {
+			s.logger.Error(err, "Failed to record order movement", "product_id", item.ProductID)
+			return 0, nil, err
+		}
+
+		if threshold != nil && quantityAfter <= *threshold && quantityAfter+item.Quantity > *threshold {
+			alerts = append(alerts, domain.LowStockAlert{
+				ProductID:        item.ProductID,
+				Description:      description,
+				Quantity:         quantityAfter,
+				ReorderThreshold: *threshold,
+				OrderID:          orderID,
+				OccurredAt:       createdAt,
+			})
+		}
+	}
+
+	payload := orderPayload{
+		OrderID:    orderID,
+		UserID:     order.UserID,
+		TotalPrice: totalPrice,
+		Discount:   discount,
+		Items:      orderLinesEventPayload(lines),
+	}
+	if err := enqueueEvent(ctx, tx, domain.AggregateOrder, orderID, domain.EventOrderCreated, payload); err != nil {
+		s.logger.Error(err, "Failed to enqueue order event", "order_id", orderID)
+		return 0, nil, err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateOrder, orderID, domain.AuditCreate, nil); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", orderID)
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Order created", "order_id", orderID, "low_stock", len(alerts))
+	return orderID, alerts, nil
+}
+
+func (s *PostgresStorage) GetOrderByID(ctx context.Context, id int64) (domain.Order, error) {
+	s.logger.Info("Fetching order", "id", id)
+	db := s.reader(ctx)
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders o
+		LEFT JOIN promo_codes p ON p.id = o.promo_code_id
+		WHERE o.id = $1
+	`
+	order, err := scanOrderRow(db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Order{}, fmt.Errorf("order not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to get order", "id", id)
+		return domain.Order{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	query = `
+		SELECT ` + orderLineColumns + `
+		FROM order_product
+		WHERE order_id = $1
+		ORDER BY product_id
+	`
+
+	rows, err := db.Query(ctx, query, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to get order products", "order_id", id)
+		return domain.Order{}, fmt.Errorf("failed to get order products: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []orderLineRow
+	for rows.Next() {
+		line, err := scanOrderLineRow(rows)
+		if err != nil {
+			s.logger.Error(err, "Failed to scan order product", "order_id", id)
+			return domain.Order{}, fmt.Errorf("failed to scan order product: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(err, "Failed to iterate order products", "order_id", id)
+		return domain.Order{}, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+	s.logger.Info("Order fetched", "id", id)
+	return order.toDomain(lines), nil
+}
+
+func (s *PostgresStorage) CancelOrder(ctx context.Context, id int64) error {
+	s.logger.Info("Cancelling order", "id", id)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		status string
+		userID int64
+	)
+	err = tx.QueryRow(ctx, `SELECT status, user_id FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&status, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("order not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to lock order", "id", id)
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	if status == domain.OrderStatusCancelled {
+		return fmt.Errorf("order %d: %w", id, domain.ErrOrderCancelled)
+	}
+
+	before, err := auditSnapshot(ctx, tx, domain.AggregateOrder, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to snapshot order", "id", id)
+		return err
+	}
+
+	query := `
+		SELECT product_id, quantity, price
+		FROM order_product
+		WHERE order_id = $1
+		ORDER BY product_id
+	`
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to get order products", "order_id", id)
+		return fmt.Errorf("failed to get order products: %w", err)
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OrderProduct, error) {
+		item := domain.OrderProduct{OrderID: id}
+		err := row.Scan(&item.ProductID, &item.Quantity, &item.Price)
+		return item, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan order products", "order_id", id)
+		return fmt.Errorf("failed to scan order products: %w", err)
+	}
+
+	for _, item := range items {
+		var quantityAfter int
+		query := `
+			UPDATE products
+			SET quantity = quantity + $1, version = version + 1
+			WHERE id = $2
+			RETURNING quantity
+		`
+		err := tx.QueryRow(ctx, query, item.Quantity, item.ProductID).Scan(&quantityAfter)
+		if err != nil {
+			s.logger.Error(err, "Failed to return product quantity", "product_id", item.ProductID)
+			return fmt.Errorf("failed to return quantity for product %d: %w", item.ProductID, err)
+		}
+
+		err = recordMovement(ctx, tx, domain.StockMovement{
+			ProductID:     item.ProductID,
+			Kind:          domain.MovementCancellation,
+			Delta:         item.Quantity,
+			QuantityAfter: quantityAfter,
+			Reason:        fmt.Sprintf("order %d cancelled", id),
+			OrderID:       &id,
+		})
+		if err != nil {
+			s.logger.Error(err, "Failed to record cancellation movement", "product_id", item.ProductID)
+			return err
+		}
+	}
+
+	query = `
+		UPDATE orders
+		SET status = $2, cancelled_at = now()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, id, domain.OrderStatusCancelled); err != nil {
+		s.logger.Error(err, "Failed to cancel order", "id", id)
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	payload := orderPayload{OrderID: id, UserID: userID, Items: orderLinesEventPayload(items)}
+	if err := enqueueEvent(ctx, tx, domain.AggregateOrder, id, domain.EventOrderCancelled, payload); err != nil {
+		s.logger.Error(err, "Failed to enqueue order event", "order_id", id)
+		return err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateOrder, id, domain.AuditUpdate, before); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Order cancelled", "id", id)
+	return nil
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// enqueueEvent writes an event to the outbox. Call it with the transaction
+// that made the change so the event exists if and only if the change does.
+func enqueueEvent(ctx context.Context, q querier, aggregateType string, aggregateID int64, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := q.Exec(ctx, query, aggregateType, aggregateID, eventType, data); err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// ClaimOutboxEvents leases up to limit due events by moving their next
+// attempt to the end of the lease, so a dispatcher that dies mid-publish
+// only delays them. Only the oldest unpublished event of every aggregate is
+// eligible, and a leased one is not due, so events of one aggregate are
+// published in order even when an earlier one keeps failing or several
+// dispatchers share the work. Publishing happens outside any transaction.
+func (s *PostgresStorage) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]domain.Event, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outbox_events
+			SET next_attempt_at = now() + $2::interval
+			WHERE id IN (
+				SELECT e.id
+				FROM outbox_events e
+				WHERE e.published_at IS NULL
+					AND e.next_attempt_at <= now()
+					AND NOT EXISTS (
+						SELECT 1
+						FROM outbox_events prev
+						WHERE prev.aggregate_type = e.aggregate_type
+							AND prev.aggregate_id = e.aggregate_id
+							AND prev.published_at IS NULL
+							AND prev.id < e.id
+					)
+				ORDER BY e.id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, aggregate_type, aggregate_id, event_type, payload, created_at, attempts
+		)
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at, attempts
+		FROM claimed
+		ORDER BY id
+	`
+	rows, err := s.db(ctx).Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Event, error) {
+		var e domain.Event
+		err := row.Scan(&e.ID, &e.AggregateType, &e.AggregateID, &e.Type, &e.Payload, &e.CreatedAt, &e.Attempts)
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkOutboxEventPublished records that a claimed event was published,
+// which makes the next event of its aggregate eligible.
+func (s *PostgresStorage) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	query := `
+		UPDATE outbox_events
+		SET published_at = now(), attempts = attempts + 1, last_error = NULL
+		WHERE id = $1
+	`
+	if _, err := s.db(ctx).Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event %d published: %w", id, err)
+	}
+	return nil
+}
+
+// RetryOutboxEvent records a failed publish of a claimed event, which is
+// due again after retryIn.
+func (s *PostgresStorage) RetryOutboxEvent(ctx context.Context, id int64, retryIn time.Duration, reason string) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1,
+			next_attempt_at = now() + $2::interval,
+			last_error = $3
+		WHERE id = $1
+	`
+	if _, err := s.db(ctx).Exec(ctx, query, id, retryIn, reason); err != nil {
+		return fmt.Errorf("failed to reschedule outbox event %d: %w", id, err)
+	}
+	return nil
+}
+
+// The payloads below are the data of the events the outbox stores and
+// webhook subscribers receive. They are kept apart from the domain types so
+// changing those does not change published events.
+
+type userPayload struct {
+	UserID    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Age       int    `json:"age"`
+	IsMarried bool   `json:"is_married"`
+}
+
+type productPayload struct {
+	ProductID   int64    `json:"product_id"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Price       float64  `json:"price"`
+}
+
+type orderPayload struct {
+	OrderID    int64              `json:"order_id"`
+	UserID     int64              `json:"user_id"`
+	TotalPrice float64            `json:"total_price,omitempty"`
+	Discount   float64            `json:"discount,omitempty"`
+	Items      []orderLinePayload `json:"items"`
+}
+
+type orderLinePayload struct {
+	OrderID        int64    `json:"order_id"`
+	ProductID      int64    `json:"product_id"`
+	ProductVersion int64    `json:"product_version"`
+	Description    string   `json:"description"`
+	Tags           []string `json:"tags"`
+	Quantity       int      `json:"quantity"`
+	Price          float64  `json:"price"`
+	Discount       float64  `json:"discount"`
+	TaxClass       string   `json:"tax_class,omitempty"`
+	TaxRate        float64  `json:"tax_rate"`
+	TaxAmount      float64  `json:"tax_amount"`
+}
+
+func userEventPayload(user domain.User) userPayload {
+	return userPayload{
+		UserID:    user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Age:       user.Age,
+		IsMarried: user.IsMarried,
+	}
+}
+
+func productEventPayload(product domain.Product) productPayload {
+	return productPayload{
+		ProductID:   product.ID,
+		Description: product.Description,
+		Tags:        product.Tags,
+		Price:       product.Price,
+	}
+}
+
+func orderLinesEventPayload(lines []domain.OrderProduct) []orderLinePayload {
+	items := make([]orderLinePayload, len(lines))
+	for i, line := range lines {
+		items[i] = orderLinePayload{
+			OrderID:        line.OrderID,
+			ProductID:      line.ProductID,
+			ProductVersion: line.ProductVersion,
+			Description:    line.Description,
+			Tags:           line.Tags,
+			Quantity:       line.Quantity,
+			Price:          line.Price,
+			Discount:       line.Discount,
+			TaxClass:       line.TaxClass,
+			TaxRate:        line.TaxRate,
+			TaxAmount:      line.TaxAmount,
+		}
+	}
+	return items
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"pet-project/internal/config"
@@ -23,7 +25,17 @@ const (
 
 type PostgresStorage struct {
 	pool   *pgxpool.Pool
+	tax    config.Tax
 	logger *logger.Logger
+
+	isolation     IsolationLevel
+	txMaxAttempts int
+
+	replicas     []*replica
+	nextReplica  atomic.Uint64
+	stopReplicas chan struct{}
+	replicasDone chan struct{}
+	closeOnce    sync.Once
 }
 
 func NewDB(ctx context.Context, cfg *config.Config, logger *logger.Logger) (*PostgresStorage, error) {
@@ -34,15 +46,11 @@ func NewDB(ctx context.Context, cfg *config.Config, logger *logger.Logger) (*Pos
 		cfg.Database.Password,
 		cfg.Database.DBName)
 
-	config, err := pgxpool.ParseConfig(connStr)
+	config, err := poolConfig(connStr, cfg.Database.MaxConns)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
 
-	config.MaxConns = int32(cfg.Database.MaxConns)
-	config.MinConns = int32(cfg.Database.MaxConns / 2)
-	config.MaxConnLifetime = 30 * time.Minute
-
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pool: %w", err)
@@ -54,32 +62,98 @@ func NewDB(ctx context.Context, cfg *config.Config, logger *logger.Logger) (*Pos
 	}
 
 	logger.Info("Database connection established", "host", cfg.Database.Host, "port", cfg.Database.Port)
-	return &PostgresStorage{pool: pool, logger: logger}, nil
+	s := &PostgresStorage{
+		pool:          pool,
+		tax:           cfg.Tax,
+		logger:        logger,
+		isolation:     IsolationLevel(cfg.Database.Isolation),
+		txMaxAttempts: cfg.Database.TxMaxAttempts,
+	}
+	if s.isolation == "" {
+		s.isolation = ReadCommitted
+	}
+
+	if err := s.openReplicas(ctx, cfg.Database.Replicas, cfg.Database.MaxConns); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if len(s.replicas) > 0 {
+		s.checkReplicas(cfg.Database.ReplicaCheckInterval)
+		s.stopReplicas = make(chan struct{})
+		s.replicasDone = make(chan struct{})
+		go s.monitorReplicas(cfg.Database.ReplicaCheckInterval, s.stopReplicas, s.replicasDone)
+		logger.Info("Read replicas configured", "count", len(s.replicas))
+	}
+	return s, nil
+}
+
+func poolConfig(connStr string, maxConns int) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	config.MaxConns = int32(maxConns)
+	config.MinConns = int32(maxConns / 2)
+	config.MaxConnLifetime = 30 * time.Minute
+	return config, nil
 }
 
 func (s *PostgresStorage) Close() {
-	s.pool.Close()
-	s.logger.Info("Database connection closed")
+	s.closeOnce.Do(func() {
+		if s.stopReplicas != nil {
+			close(s.stopReplicas)
+			<-s.replicasDone
+		}
+		for _, r := range s.replicas {
+			r.pool.Close()
+		}
+		s.pool.Close()
+		s.logger.Info("Database connection closed")
+	})
 }
 
 func (s *PostgresStorage) CreateUser(ctx context.Context, user domain.User) (int64, error) {
 	s.logger.Info("Creating user", "first_name", user.FirstName, "last_name", user.LastName)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO users (first_name, last_name, age, is_married, password)
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
 	var id int64
-	err := s.pool.QueryRow(ctx, query, user.FirstName, user.LastName, user.Age, user.IsMarried, user.Password).Scan(&id)
+	err = tx.QueryRow(ctx, query, user.FirstName, user.LastName, user.Age, user.IsMarried, user.Password).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
-			return 0, fmt.Errorf("user with name %s %s already exists", user.FirstName, user.LastName)
+			return 0, fmt.Errorf("user with name %s %s already exists: %w", user.FirstName, user.LastName, domain.ErrAlreadyExists)
 		}
 		s.logger.Error(err, "Failed to create user", "first_name", user.FirstName, "last_name", user.LastName)
 		return 0, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	user.ID = id
+	if err := enqueueEvent(ctx, tx, domain.AggregateUser, id, domain.EventUserRegistered, userEventPayload(user)); err != nil {
+		s.logger.Error(err, "Failed to enqueue user event", "id", id)
+		return 0, err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateUser, id, domain.AuditCreate, nil); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	s.logger.Info("User created", "id", id)
 	return id, nil
 }
@@ -87,170 +161,208 @@ func (s *PostgresStorage) CreateUser(ctx context.Context, user domain.User) (int
 func (s *PostgresStorage) GetUserByID(ctx context.Context, id int64) (domain.User, error) {
 	s.logger.Info("Fetching user", "id", id)
 	query := `
-		SELECT id, first_name, last_name, full_name, age, is_married, password
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-	var user domain.User
-	err := s.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.FirstName,
-		&user.LastName,
-		&user.FullName,
-		&user.Age,
-		&user.IsMarried,
-		&user.Password,
-	)
+	row, err := scanUserRow(s.reader(ctx).QueryRow(ctx, query, id))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.User{}, fmt.Errorf("user not found")
+		return domain.User{}, fmt.Errorf("user not found: %w", domain.ErrNotFound)
 	}
 	if err != nil {
 		s.logger.Error(err, "Failed to get user", "id", id)
 		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
 	}
+	user := row.toDomain()
 
 	s.logger.Info("User fetched", "id", id, "full_name", user.FullName)
 	return user, nil
 }
 
-func (s *PostgresStorage) CreateProduct(ctx context.Context, product domain.Product) (int64, error) {
-	s.logger.Info("Creating product", "description", product.Description)
-	query := `
-		INSERT INTO products (description, tags, quantity, price)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
-
-	var id int64
-	err := s.pool.QueryRow(ctx, query, product.Description, product.Tags, product.Quantity, product.Price).Scan(&id)
+func (s *PostgresStorage) UpdateUser(ctx context.Context, id, version int64, update domain.UserUpdate) (domain.User, error) {
+	s.logger.Info("Updating user", "id", id, "version", version)
+	tx, err := s.begin(ctx)
 	if err != nil {
-		s.logger.Error(err, "Failed to create product", "description", product.Description)
-		return 0, fmt.Errorf("failed to create product %w", err)
+		s.logger.Error(err, "Failed to start transaction")
+		return domain.User{}, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	s.logger.Info("Product created", "id", id)
-	return id, nil
-}
+	before, err := auditSnapshot(ctx, tx, domain.AggregateUser, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to snapshot user", "id", id)
+		return domain.User{}, err
+	}
 
-func (s *PostgresStorage) GetProductByID(ctx context.Context, id int64) (domain.Product, error) {
-	s.logger.Info("Fetching product", "id", id)
 	query := `
-	SELECT id, description, tags, quantity, price
-	FROM products
-	WHERE id = $1
+		UPDATE users
+		SET first_name = COALESCE($3, first_name),
+			last_name = COALESCE($4, last_name),
+			is_married = COALESCE($5, is_married),
+			version = version + 1
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+		RETURNING ` + userColumns + `
 	`
-
-	var product domain.Product
-	err := s.pool.QueryRow(ctx, query, id).Scan(
-		&product.ID,
-		&product.Description,
-		&product.Tags,
-		&product.Quantity,
-		&product.Price,
-	)
+	row, err := scanUserRow(tx.QueryRow(ctx, query, id, version, update.FirstName, update.LastName, update.IsMarried))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.Product{}, fmt.Errorf("product not found")
+		return domain.User{}, s.staleOrMissing(ctx, "users", id, version)
 	}
 	if err != nil {
-		s.logger.Error(err, "Failed to  get product", "id", id)
-		return domain.Product{}, fmt.Errorf("failed to get product: %w", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
+			return domain.User{}, fmt.Errorf("user with this name already exists: %w", domain.ErrAlreadyExists)
+		}
+		s.logger.Error(err, "Failed to update user", "id", id)
+		return domain.User{}, fmt.Errorf("failed to update user: %w", err)
 	}
+	user := row.toDomain()
 
-	s.logger.Info("Product fetched", "id", id, "description", product.Description)
-	return product, nil
+	if err := enqueueEvent(ctx, tx, domain.AggregateUser, id, domain.EventUserUpdated, userEventPayload(user)); err != nil {
+		s.logger.Error(err, "Failed to enqueue user event", "id", id)
+		return domain.User{}, err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateUser, id, domain.AuditUpdate, before); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return domain.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return domain.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("User updated", "id", id, "full_name", user.FullName)
+	return user, nil
 }
 
-func (s *PostgresStorage) UpdateProductQuantity(ctx context.Context, id int64, quantity int) error {
-	s.logger.Info("Update product quantity", "id", id, "quantity", quantity)
+// staleOrMissing explains why a versioned UPDATE matched no rows.
+func (s *PostgresStorage) staleOrMissing(ctx context.Context, table string, id, version int64) error {
+	query := fmt.Sprintf(`SELECT version FROM %s WHERE id = $1`, table)
+	if table == "users" {
+		query += ` AND deleted_at IS NULL`
+	}
+
+	var current int64
+	err := s.db(ctx).QueryRow(ctx, query, id).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s row %d not found: %w", table, id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check %s row %d version: %w", table, id, err)
+	}
+	return fmt.Errorf("%s row %d is at version %d, not %d: %w", table, id, current, version, domain.ErrVersionConflict)
+}
+
+func (s *PostgresStorage) DeleteUser(ctx context.Context, id int64) error {
+	s.logger.Info("Deleting user", "id", id)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := auditSnapshot(ctx, tx, domain.AggregateUser, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to snapshot user", "id", id)
+		return err
+	}
+
 	query := `
-	UPDATE products
-	SET quantity = $1
-	WHERE id = $2
+		UPDATE users
+		SET deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-
-	result, err := s.pool.Exec(ctx, query, quantity, id)
+	result, err := tx.Exec(ctx, query, id)
 	if err != nil {
-		s.logger.Error(err, "Failed to update product quantity", "id", id)
-		return fmt.Errorf("failed to update product quantity: %w", err)
+		s.logger.Error(err, "Failed to delete user", "id", id)
+		return fmt.Errorf("failed to delete user: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("product with id %d not found", id)
+		return fmt.Errorf("user not found: %w", domain.ErrNotFound)
 	}
 
-	s.logger.Info("Product quantity updated", "id", id)
+	if err := enqueueEvent(ctx, tx, domain.AggregateUser, id, domain.EventUserDeleted, userPayload{UserID: id}); err != nil {
+		s.logger.Error(err, "Failed to enqueue user event", "id", id)
+		return err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateUser, id, domain.AuditDelete, before); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("User deleted", "id", id)
 	return nil
 }
 
-func (s *PostgresStorage) CreateOrder(ctx context.Context, userID int64, orderProducts []domain.OrderProduct, totalPrice float64) (int64, error) {
-	s.logger.Info("Creating order", "user_id", userID, "total_price", totalPrice)
-	tx, err := s.pool.Begin(ctx)
+func (s *PostgresStorage) CreateProduct(ctx context.Context, product domain.Product) (int64, error) {
+	s.logger.Info("Creating product", "description", product.Description)
+	if product.Tags == nil {
+		product.Tags = []string{}
+	}
+	if product.TaxClass == "" {
+		product.TaxClass = s.tax.DefaultClass
+	}
+	if _, err := s.taxRate(product.TaxClass); err != nil {
+		return 0, err
+	}
+	tx, err := s.begin(ctx)
 	if err != nil {
 		s.logger.Error(err, "Failed to start transaction")
 		return 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	var orderID int64
 	query := `
-	INSERT INTO orders (user_id, created_at, total_price)
-	VALUES ($1, $2, $3)
-	RETURNING id
-`
+		INSERT INTO products (sku, description, tags, quantity, price, tax_class, reorder_threshold)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, $7)
+		RETURNING id`
 
-	err = tx.QueryRow(ctx, query, userID, time.Now(), totalPrice).Scan(&orderID)
+	var id int64
+	err = tx.QueryRow(ctx, query, product.SKU, product.Description, product.Tags, product.Quantity, product.Price, product.TaxClass, product.ReorderThreshold).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == ErrCodeForeignKeyViolation {
-			return 0, fmt.Errorf("user with id %d not found", userID)
-		}
-		s.logger.Error(err, "Failed to create order", "user_id", userID)
-		return 0, fmt.Errorf("failed to create order: %w", err)
-	}
-
-	for _, op := range orderProducts {
-		var availableQty int
-		var price float64
-		query := `
-			SELECT quantity, price
-			FROM products
-			WHERE id = $1
-			FOR UPDATE
-		`
-
-		err := tx.QueryRow(ctx, query, op.ProductID).Scan(&availableQty, &price)
-		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, fmt.Errorf("product with id %d not found", op.ProductID)
-		}
-		if err != nil {
-			s.logger.Error(err, "Failed to check product", "product_id", op.ProductID)
-			return 0, fmt.Errorf("failed to check product %d: %w", op.ProductID, err)
-		}
-		if availableQty < op.Quantity {
-			return 0, fmt.Errorf("not enough quantity for product %d: available %d, requested %d", op.ProductID, availableQty, op.Quantity)
+		if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
+			return 0, fmt.Errorf("product with sku %s already exists: %w", product.SKU, domain.ErrAlreadyExists)
 		}
+		s.logger.Error(err, "Failed to create product", "description", product.Description)
+		return 0, fmt.Errorf("failed to create product %w", err)
+	}
 
-		query = `
-			INSERT INTO order_product (order_id, product_id, quantity, price)
-			VALUES ($1, $2, $3, $4)
-		`
-		_, err = tx.Exec(ctx, query, orderID, op.ProductID, op.Quantity, price)
-		if err != nil {
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
-				return 0, fmt.Errorf("product %d already exists in order %d", op.ProductID, orderID)
-			}
-			s.logger.Error(err, "Failed to add product to order", "product_id", op.ProductID)
-			return 0, fmt.Errorf("failed to add product %d to order: %w", op.ProductID, err)
-		}
+	product.ID = id
+	if err := enqueueEvent(ctx, tx, domain.AggregateProduct, id, domain.EventProductCreated, productEventPayload(product)); err != nil {
+		s.logger.Error(err, "Failed to enqueue product event", "id", id)
+		return 0, err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateProduct, id, domain.AuditCreate, nil); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return 0, err
+	}
 
-		query = `
-			UPDATE products
-			SET quantity = quantity - $1
-			WHERE id = $2
-		`
-		_, err = tx.Exec(ctx, query, op.Quantity, op.ProductID)
+	if err := recordProductVersion(ctx, tx, id); err != nil {
+		s.logger.Error(err, "Failed to record product version", "id", id)
+		return 0, err
+	}
+
+	if product.Quantity != 0 {
+		err = recordMovement(ctx, tx, domain.StockMovement{
+			ProductID:     id,
+			Kind:          domain.MovementInitial,
+			Delta:         product.Quantity,
+			QuantityAfter: product.Quantity,
+			Reason:        "initial stock",
+		})
 		if err != nil {
-			s.logger.Error(err, "Failed to update product quantity", "product_id", op.ProductID)
-			return 0, fmt.Errorf("failed to update quantity for product %d: %w", op.ProductID, err)
+			s.logger.Error(err, "Failed to record initial stock", "id", id)
+			return 0, err
 		}
 	}
 
@@ -259,52 +371,28 @@ func (s *PostgresStorage) CreateOrder(ctx context.Context, userID int64, orderPr
 		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	s.logger.Info("Order created", "order_id", orderID)
-	return orderID, nil
+	s.logger.Info("Product created", "id", id)
+	return id, nil
 }
 
-func (s *PostgresStorage) GetOrderByID(ctx context.Context, id int64) (domain.Order, error) {
-	s.logger.Info("Fetching order", "id", id)
+func (s *PostgresStorage) GetProductByID(ctx context.Context, id int64) (domain.Product, error) {
+	s.logger.Info("Fetching product", "id", id)
 	query := `
-		SELECT id, user_id, created_at, total_price
-		FROM orders
-		WHERE id = $1
+	SELECT ` + productColumns + `
+	FROM products
+	WHERE id = $1
 	`
-	var order domain.Order
-	err := s.pool.QueryRow(ctx, query, id).Scan(&order.ID, &order.UserID, &order.CreatedAt, &order.TotalPrice)
+
+	row, err := scanProductRow(s.reader(ctx).QueryRow(ctx, query, id))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.Order{}, fmt.Errorf("order not found")
+		return domain.Product{}, fmt.Errorf("product not found: %w", domain.ErrNotFound)
 	}
 	if err != nil {
-		s.logger.Error(err, "Failed to get order", "id", id)
-		return domain.Order{}, fmt.Errorf("failed to get order: %w", err)
-	}
-
-	query = `
-		SELECT order_id, product_id, quantity, price
-		FROM order_products
-		WHERE order_id = $1
-	`
-
-	rows, err := s.pool.Query(ctx, query, id)
-	if err != nil {
-		s.logger.Error(err, "Failed to get order products", "order_id", id)
-		return domain.Order{}, fmt.Errorf("failed to get order products: %w", err)
+		s.logger.Error(err, "Failed to  get product", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to get product: %w", err)
 	}
-	defer rows.Close()
+	product := row.toDomain()
 
-	for rows.Next() {
-		var op domain.OrderProduct
-		if err := rows.Scan(&op.OrderID, &op.ProductID, &op.Quantity, &op.Price); err != nil {
-			s.logger.Error(err, "Failed to scan order product", "order_id", id)
-			return domain.Order{}, fmt.Errorf("failed to scan order product: %w", err)
-		}
-		order.OrderProduct = append(order.OrderProduct, op)
-	}
-	if err := rows.Err(); err != nil {
-		s.logger.Error(err, "Failed to iterate order products", "order_id", id)
-		return domain.Order{}, fmt.Errorf("failed to iterate rows: %w", err)
-	}
-	s.logger.Info("Order fetched", "id", id)
-	return order, nil
+	s.logger.Info("Product fetched", "id", id, "description", product.Description)
+	return product, nil
 }
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recordProductVersion saves the product as it now stands as a new version.
+// Call it after the change, with the transaction that made it.
+func recordProductVersion(ctx context.Context, q querier, productID int64) error {
+	query := `
+		INSERT INTO product_versions (product_id, version, description, tags, price, tax_class, reorder_threshold)
+		SELECT id, version, description, tags, price, tax_class, reorder_threshold
+		FROM products
+		WHERE id = $1
+	`
+	if _, err := q.Exec(ctx, query, productID); err != nil {
+		return fmt.Errorf("failed to record version of product %d: %w", productID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) ListProductVersions(ctx context.Context, productID int64) ([]domain.ProductVersion, error) {
+	s.logger.Info("Listing product versions", "product_id", productID)
+	query := `
+		SELECT ` + productVersionColumns + `
+		FROM product_versions
+		WHERE product_id = $1
+		ORDER BY version
+	`
+	rows, err := s.reader(ctx).Query(ctx, query, productID)
+	if err != nil {
+		s.logger.Error(err, "Failed to list product versions", "product_id", productID)
+		return nil, fmt.Errorf("failed to list product versions: %w", err)
+	}
+	versions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ProductVersion, error) {
+		r, err := scanProductVersionRow(row)
+		return r.toDomain(), err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan product versions", "product_id", productID)
+		return nil, fmt.Errorf("failed to scan product versions: %w", err)
+	}
+	// Every product has at least one version.
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("product not found: %w", domain.ErrNotFound)
+	}
+
+	s.logger.Info("Product versions listed", "product_id", productID, "count", len(versions))
+	return versions, nil
+}
+
+// GetProductAt returns the product as it stood at the given time: the
+// version then in effect, with the quantity the stock ledger had recorded.
+func (s *PostgresStorage) GetProductAt(ctx context.Context, id int64, at time.Time) (domain.Product, error) {
+	s.logger.Info("Fetching product version", "id", id, "at", at)
+	query := `
+		SELECT v.product_id, p.sku, v.description, v.tags,
+			COALESCE((
+				SELECT m.quantity_after
+				FROM stock_movements m
+				WHERE m.product_id = v.product_id AND m.created_at <= $2
+				ORDER BY m.id DESC
+				LIMIT 1
+			), 0),
+			v.price, v.tax_class, v.reorder_threshold, v.version
+		FROM product_versions v
+		JOIN products p ON p.id = v.product_id
+		WHERE v.product_id = $1 AND v.valid_from <= $2
+		ORDER BY v.version DESC
+		LIMIT 1
+	`
+	row, err := scanProductRow(s.reader(ctx).QueryRow(ctx, query, id, at))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Product{}, fmt.Errorf("product not found at %s: %w", at.Format(time.RFC3339), domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to get product version", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to get product version: %w", err)
+	}
+	product := row.toDomain()
+
+	s.logger.Info("Product version fetched", "id", id, "version", product.Version)
+	return product, nil
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *PostgresStorage) UpdateProduct(ctx context.Context, id, version int64, update domain.ProductUpdate) (domain.Product, error) {
+	s.logger.Info("Updating product", "id", id, "version", version)
+	if update.TaxClass != nil {
+		if _, err := s.taxRate(*update.TaxClass); err != nil {
+			return domain.Product{}, err
+		}
+	}
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return domain.Product{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := auditSnapshot(ctx, tx, domain.AggregateProduct, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to snapshot product", "id", id)
+		return domain.Product{}, err
+	}
+
+	query := `
+		UPDATE products
+		SET description = COALESCE($3, description),
+			tags = COALESCE($4, tags),
+			price = COALESCE($5, price),
+			reorder_threshold = COALESCE($6, reorder_threshold),
+			tax_class = COALESCE($7, tax_class),
+			version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING ` + productColumns + `
+	`
+	row, err := scanProductRow(tx.QueryRow(ctx, query, id, version, update.Description, update.Tags, update.Price, update.ReorderThreshold, update.TaxClass))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Product{}, s.staleOrMissing(ctx, "products", id, version)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to update product", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to update product: %w", err)
+	}
+	product := row.toDomain()
+
+	if err := enqueueEvent(ctx, tx, domain.AggregateProduct, id, domain.EventProductUpdated, productEventPayload(product)); err != nil {
+		s.logger.Error(err, "Failed to enqueue product event", "id", id)
+		return domain.Product{}, err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateProduct, id, domain.AuditUpdate, before); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return domain.Product{}, err
+	}
+
+	if err := recordProductVersion(ctx, tx, id); err != nil {
+		s.logger.Error(err, "Failed to record product version", "id", id)
+		return domain.Product{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return domain.Product{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Product updated", "id", id, "version", product.Version)
+	return product, nil
+}
+
+func (s *PostgresStorage) AdjustProductStock(ctx context.Context, id int64, adjustment domain.StockAdjustment) (domain.Product, error) {
+	s.logger.Info("Adjusting product stock", "id", id, "delta", adjustment.Delta, "reason", adjustment.Reason)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return domain.Product{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := auditSnapshot(ctx, tx, domain.AggregateProduct, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to snapshot product", "id", id)
+		return domain.Product{}, err
+	}
+
+	query := `
+		UPDATE products
+		SET quantity = quantity + $2,
+			version = version + 1
+		WHERE id = $1 AND quantity + $2 >= 0
+		RETURNING ` + productColumns + `
+	`
+	row, err := scanProductRow(tx.QueryRow(ctx, query, id, adjustment.Delta))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var quantity int
+		err := tx.QueryRow(ctx, `SELECT quantity FROM products WHERE id = $1`, id).Scan(&quantity)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Product{}, fmt.Errorf("product not found: %w", domain.ErrNotFound)
+		}
+		if err != nil {
+			return domain.Product{}, fmt.Errorf("failed to check product %d: %w", id, err)
+		}
+		return domain.Product{}, fmt.Errorf("product %d has %d in stock, cannot apply %d: %w", id, quantity, adjustment.Delta, domain.ErrInsufficientStock)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to adjust product stock", "id", id)
+		return domain.Product{}, fmt.Errorf("failed to adjust product stock: %w", err)
+	}
+	product := row.toDomain()
+
+	err = recordMovement(ctx, tx, domain.StockMovement{
+		ProductID:     id,
+		Kind:          adjustment.Kind,
+		Delta:         adjustment.Delta,
+		QuantityAfter: product.Quantity,
+		Reason:        adjustment.Reason,
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to record stock adjustment", "id", id)
+		return domain.Product{}, err
+	}
+
+	if err := recordAudit(ctx, tx, domain.AggregateProduct, id, domain.AuditUpdate, before); err != nil {
+		s.logger.Error(err, "Failed to record audit entry", "id", id)
+		return domain.Product{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return domain.Product{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Product stock adjusted", "id", id, "quantity", product.Quantity)
+	return product, nil
+}
+
+func (s *PostgresStorage) ListLowStockProducts(ctx context.Context) ([]domain.Product, error) {
+	s.logger.Info("Listing low stock products")
+	query := `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE quantity <= reorder_threshold
+		ORDER BY quantity - reorder_threshold, id
+	`
+	rows, err := s.reader(ctx).Query(ctx, query)
+	if err != nil {
+		s.logger.Error(err, "Failed to list low stock products")
+		return nil, fmt.Errorf("failed to list low stock products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []domain.Product{}
+	for rows.Next() {
+		row, err := scanProductRow(rows)
+		if err != nil {
+			s.logger.Error(err, "Failed to scan product")
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, row.toDomain())
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(err, "Failed to iterate low stock products")
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	s.logger.Info("Low stock products listed", "count", len(products))
+	return products, nil
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const promoCodeColumns = `
+	id, code, kind, value, min_order_value, valid_from, valid_until,
+	max_uses, max_uses_per_user, product_ids, tags, created_at
+`
+
+func scanPromoCode(row pgx.Row) (domain.PromoCode, error) {
+	var p domain.PromoCode
+	err := row.Scan(
+		&p.ID, &p.Code, &p.Kind, &p.Value, &p.MinOrderValue, &p.ValidFrom, &p.ValidUntil,
+		&p.MaxUses, &p.MaxUsesPerUser, &p.ProductIDs, &p.Tags, &p.CreatedAt,
+	)
+	return p, err
+}
+
+func (s *PostgresStorage) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	s.logger.Info("Creating promo code", "code", promo.Code)
+	if promo.ProductIDs == nil {
+		promo.ProductIDs = []int64{}
+	}
+	if promo.Tags == nil {
+		promo.Tags = []string{}
+	}
+
+	query := `
+		INSERT INTO promo_codes (code, kind, value, min_order_value, valid_from, valid_until,
+			max_uses, max_uses_per_user, product_ids, tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + promoCodeColumns
+	created, err := scanPromoCode(s.db(ctx).QueryRow(ctx, query,
+		promo.Code, promo.Kind, promo.Value, promo.MinOrderValue, promo.ValidFrom, promo.ValidUntil,
+		promo.MaxUses, promo.MaxUsesPerUser, promo.ProductIDs, promo.Tags,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == ErrCodeUniqueViolation {
+			return domain.PromoCode{}, fmt.Errorf("promo code %s already exists: %w", promo.Code, domain.ErrAlreadyExists)
+		}
+		s.logger.Error(err, "Failed to create promo code", "code", promo.Code)
+		return domain.PromoCode{}, fmt.Errorf("failed to create promo code: %w", err)
+	}
+
+	s.logger.Info("Promo code created", "id", created.ID)
+	return created, nil
+}
+
+func (s *PostgresStorage) GetPromoCode(ctx context.Context, id int64) (domain.PromoCode, error) {
+	s.logger.Info("Fetching promo code", "id", id)
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE id = $1`
+	promo, err := scanPromoCode(s.reader(ctx).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.PromoCode{}, fmt.Errorf("promo code not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to get promo code", "id", id)
+		return domain.PromoCode{}, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return promo, nil
+}
+
+func (s *PostgresStorage) ListPromoCodes(ctx context.Context) ([]domain.PromoCode, error) {
+	s.logger.Info("Listing promo codes")
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes ORDER BY id`
+	rows, err := s.reader(ctx).Query(ctx, query)
+	if err != nil {
+		s.logger.Error(err, "Failed to list promo codes")
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	promos, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.PromoCode, error) {
+		return scanPromoCode(row)
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan promo codes")
+		return nil, fmt.Errorf("failed to scan promo codes: %w", err)
+	}
+	return promos, nil
+}
+
+// promoLine is an order line as far as promo eligibility is concerned.
+type promoLine struct {
+	productID int64
+	tags      []string
+	amount    float64
+}
+
+// redeemPromoCode checks that code may be used for the order and returns it
+// with the discount. The code row stays locked until the order commits,
+// so concurrent orders cannot both take the last permitted use.
+func redeemPromoCode(ctx context.Context, q querier, code string, userID int64, lines []promoLine, subtotal float64, now time.Time) (domain.PromoCode, float64, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE upper(code) = upper($1) FOR UPDATE`
+	promo, err := scanPromoCode(q.QueryRow(ctx, query, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.PromoCode{}, 0, fmt.Errorf("promo code %s does not exist: %w", code, domain.ErrPromoNotApplicable)
+	}
+	if err != nil {
+		return domain.PromoCode{}, 0, fmt.Errorf("failed to get promo code %s: %w", code, err)
+	}
+
+	if promo.ValidFrom != nil && now.Before(*promo.ValidFrom) {
+		return domain.PromoCode{}, 0, fmt.Errorf("promo code %s is not valid yet: %w", code, domain.ErrPromoNotApplicable)
+	}
+	if promo.ValidUntil != nil && !now.Before(*promo.ValidUntil) {
+		return domain.PromoCode{}, 0, fmt.Errorf("promo code %s has expired: %w", code, domain.ErrPromoNotApplicable)
+	}
+	if subtotal < promo.MinOrderValue {
+		return domain.PromoCode{}, 0, fmt.Errorf("promo code %s requires an order of at least %.2f: %w", code, promo.MinOrderValue, domain.ErrPromoNotApplicable)
+	}
+
+	if promo.MaxUses != nil || promo.MaxUsesPerUser != nil {
+		var uses, userUses int
+		query := `
+			SELECT count(*), count(*) FILTER (WHERE user_id = $2)
+			FROM orders
+			WHERE promo_code_id = $1 AND status <> 'cancelled'
+		`
+		if err := q.QueryRow(ctx, query, promo.ID, userID).Scan(&uses, &userUses); err != nil {
+			return domain.PromoCode{}, 0, fmt.Errorf("failed to count uses of promo code %s: %w", code, err)
+		}
+		if promo.MaxUses != nil && uses >= *promo.MaxUses {
+			return domain.PromoCode{}, 0, fmt.Errorf("promo code %s has been used up: %w", code, domain.ErrPromoExhausted)
+		}
+		if promo.MaxUsesPerUser != nil && userUses >= *promo.MaxUsesPerUser {
+			return domain.PromoCode{}, 0, fmt.Errorf("user %d has already used promo code %s: %w", userID, code, domain.ErrPromoExhausted)
+		}
+	}
+
+	discount := promoDiscount(promo, lines)
+	if discount == 0 {
+		return domain.PromoCode{}, 0, fmt.Errorf("promo code %s does not apply to any ordered product: %w", code, domain.ErrPromoNotApplicable)
+	}
+	return promo, discount, nil
+}
+
+// promoDiscount applies promo to the eligible lines, never discounting more
+// than they cost.
+func promoDiscount(promo domain.PromoCode, lines []promoLine) float64 {
+	var eligible float64
+	for _, line := range lines {
+		if promoApplies(promo, line) {
+			eligible += line.amount
+		}
+	}
+
+	var discount float64
+	switch promo.Kind {
+	case domain.PromoPercent:
+		discount = eligible * promo.Value / 100
+	case domain.PromoFixed:
+		discount = promo.Value
+	}
+	return roundMoney(min(discount, eligible))
+}
+
+func promoApplies(promo domain.PromoCode, line promoLine) bool {
+	if len(promo.ProductIDs) == 0 && len(promo.Tags) == 0 {
+		return true
+	}
+	if slices.Contains(promo.ProductIDs, line.productID) {
+		return true
+	}
+	return slices.ContainsFunc(line.tags, func(tag string) bool {
+		return slices.Contains(promo.Tags, tag)
+	})
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
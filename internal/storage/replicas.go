@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"pet-project/internal/reqmeta"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replica is a read-only copy of the database. Reads are sent to it only
+// while its last health check passed.
+type replica struct {
+	host    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// reader is what a read-only query runs on: the unit of work's
+// transaction when ctx carries one, so it sees the work's own writes, or
+// else readPool.
+func (s *PostgresStorage) reader(ctx context.Context) querier {
+	if uow := unitOfWorkFrom(ctx); uow != nil {
+		return uow.tx
+	}
+	return s.readPool(ctx)
+}
+
+// readPool returns the next healthy replica in turn, or the primary when
+// the request asks to read from it or no replica is healthy.
+func (s *PostgresStorage) readPool(ctx context.Context) *pgxpool.Pool {
+	if reqmeta.From(ctx).ReadPrimary || len(s.replicas) == 0 {
+		return s.pool
+	}
+	start := s.nextReplica.Add(1)
+	for i := range uint64(len(s.replicas)) {
+		r := s.replicas[(start+i)%uint64(len(s.replicas))]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return s.pool
+}
+
+func (s *PostgresStorage) openReplicas(ctx context.Context, dsns []string, maxConns int) error {
+	for _, dsn := range dsns {
+		config, err := poolConfig(dsn, maxConns)
+		if err != nil {
+			return fmt.Errorf("failed to parse replica config: %w", err)
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, config)
+		if err != nil {
+			return fmt.Errorf("failed to create replica pool %s: %w", config.ConnConfig.Host, err)
+		}
+		r := &replica{host: config.ConnConfig.Host, pool: pool}
+		r.healthy.Store(true)
+		s.replicas = append(s.replicas, r)
+	}
+	return nil
+}
+
+// monitorReplicas checks the replicas every interval until stop is closed.
+func (s *PostgresStorage) monitorReplicas(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.checkReplicas(interval)
+		}
+	}
+}
+
+func (s *PostgresStorage) checkReplicas(timeout time.Duration) {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := r.pool.Ping(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			s.logger.Info("Replica is up, routing reads to it", "host", r.host)
+		} else {
+			s.logger.Error(err, "Replica is down, routing its reads elsewhere", "host", r.host)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Every report reads the lines of the orders that were not cancelled,
+// with the prices the lines recorded.
+const reportLines = `
+	FROM orders o
+	JOIN order_product op ON op.order_id = o.id
+	WHERE o.status <> 'cancelled' AND o.created_at >= $1 AND o.created_at < $2
+`
+
+// RevenueByPeriod sums sales per period in filter.TimeZone. Periods without
+// sales are included with zeros so the rows form a continuous series.
+func (s *PostgresStorage) RevenueByPeriod(ctx context.Context, filter domain.ReportFilter) ([]domain.RevenueRow, error) {
+	s.logger.Info("Reporting revenue", "from", filter.From, "to", filter.To, "period", filter.Period, "time_zone", filter.TimeZone)
+	query := `
+		WITH sales AS (
+			SELECT date_trunc($3, o.created_at AT TIME ZONE $4) AS period_start,
+				count(DISTINCT o.id) AS orders,
+				sum(op.quantity) AS items,
+				sum(op.price * op.quantity) AS gross_sales,
+				sum(op.discount) AS discount,
+				sum(op.tax_amount) AS tax
+			` + reportLines + `
+			GROUP BY 1
+		)
+		SELECT p.period_start,
+			COALESCE(s.orders, 0),
+			COALESCE(s.items, 0),
+			COALESCE(s.gross_sales, 0),
+			COALESCE(s.discount, 0),
+			COALESCE(s.gross_sales - s.discount, 0),
+			COALESCE(s.tax, 0)
+		FROM generate_series(
+			date_trunc($3, $1::timestamptz AT TIME ZONE $4),
+			($2::timestamptz AT TIME ZONE $4) - interval '1 microsecond',
+			('1 ' || $3)::interval
+		) AS p (period_start)
+		LEFT JOIN sales s ON s.period_start = p.period_start
+		ORDER BY p.period_start
+	`
+	rows, err := s.reader(ctx).Query(ctx, query, filter.From, filter.To, filter.Period, filter.TimeZone)
+	if err != nil {
+		s.logger.Error(err, "Failed to report revenue")
+		return nil, fmt.Errorf("failed to report revenue: %w", err)
+	}
+	report, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.RevenueRow, error) {
+		var (
+			r     domain.RevenueRow
+			start time.Time
+		)
+		err := row.Scan(&start, &r.Orders, &r.Items, &r.GrossSales, &r.Discount, &r.Revenue, &r.Tax)
+		r.PeriodStart = start.Format(time.DateOnly)
+		return r, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan revenue report")
+		return nil, fmt.Errorf("failed to scan revenue report: %w", err)
+	}
+
+	s.logger.Info("Revenue reported", "periods", len(report))
+	return report, nil
+}
+
+func (s *PostgresStorage) TopProducts(ctx context.Context, filter domain.ReportFilter) ([]domain.ProductSales, error) {
+	s.logger.Info("Reporting top products", "from", filter.From, "to", filter.To, "limit", filter.Limit)
+	query := `
+		SELECT op.product_id,
+			(array_agg(op.description ORDER BY o.created_at DESC))[1],
+			sum(op.quantity),
+			count(DISTINCT o.id),
+			sum(op.price * op.quantity - op.discount) AS revenue
+		` + reportLines + `
+		GROUP BY op.product_id
+		ORDER BY revenue DESC, op.product_id
+		LIMIT $3
+	`
+	rows, err := s.reader(ctx).Query(ctx, query, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		s.logger.Error(err, "Failed to report top products")
+		return nil, fmt.Errorf("failed to report top products: %w", err)
+	}
+	products, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ProductSales, error) {
+		var p domain.ProductSales
+		err := row.Scan(&p.ProductID, &p.Description, &p.Quantity, &p.Orders, &p.Revenue)
+		return p, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan top products")
+		return nil, fmt.Errorf("failed to scan top products: %w", err)
+	}
+
+	s.logger.Info("Top products reported", "count", len(products))
+	return products, nil
+}
+
+func (s *PostgresStorage) TopCustomers(ctx context.Context, filter domain.ReportFilter) ([]domain.CustomerSales, error) {
+	s.logger.Info("Reporting top customers", "from", filter.From, "to", filter.To, "limit", filter.Limit)
+	query := `
+		SELECT t.user_id, u.first_name, u.last_name, t.orders, t.items, t.revenue
+		FROM (
+			SELECT o.user_id,
+				count(DISTINCT o.id) AS orders,
+				sum(op.quantity) AS items,
+				sum(op.price * op.quantity - op.discount) AS revenue
+			` + reportLines + `
+			GROUP BY o.user_id
+			ORDER BY revenue DESC, o.user_id
+			LIMIT $3
+		) t
+		JOIN users u ON u.id = t.user_id
+		ORDER BY t.revenue DESC, t.user_id
+	`
+	rows, err := s.reader(ctx).Query(ctx, query, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		s.logger.Error(err, "Failed to report top customers")
+		return nil, fmt.Errorf("failed to report top customers: %w", err)
+	}
+	customers, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.CustomerSales, error) {
+		var c domain.CustomerSales
+		err := row.Scan(&c.UserID, &c.FirstName, &c.LastName, &c.Orders, &c.Items, &c.Revenue)
+		return c, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan top customers")
+		return nil, fmt.Errorf("failed to scan top customers: %w", err)
+	}
+
+	s.logger.Info("Top customers reported", "count", len(customers))
+	return customers, nil
+}
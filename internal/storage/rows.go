@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Row models mirror what the queries select, nullable columns included;
+// their toDomain methods are the one place a column change has to be
+// absorbed before it could reach the service or the API.
+
+// userColumns leaves out the password: it is written but never read back.
+const userColumns = `id, first_name, last_name, full_name, age, is_married, version`
+
+type userRow struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	FullName  string
+	Age       int
+	IsMarried bool
+	Version   int64
+}
+
+func scanUserRow(row pgx.Row) (userRow, error) {
+	var r userRow
+	err := row.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FullName, &r.Age, &r.IsMarried, &r.Version)
+	return r, err
+}
+
+func (r userRow) toDomain() domain.User {
+	return domain.User{
+		ID:        r.ID,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		FullName:  r.FullName,
+		Age:       r.Age,
+		IsMarried: r.IsMarried,
+		Version:   r.Version,
+	}
+}
+
+const productColumns = `id, sku, description, tags, quantity, price, tax_class, reorder_threshold, version`
+
+type productRow struct {
+	ID               int64
+	SKU              *string
+	Description      string
+	Tags             []string
+	Quantity         int
+	Price            float64
+	TaxClass         string
+	ReorderThreshold *int
+	Version          int64
+}
+
+func scanProductRow(row pgx.Row) (productRow, error) {
+	var r productRow
+	err := row.Scan(&r.ID, &r.SKU, &r.Description, &r.Tags, &r.Quantity, &r.Price, &r.TaxClass, &r.ReorderThreshold, &r.Version)
+	return r, err
+}
+
+func (r productRow) toDomain() domain.Product {
+	product := domain.Product{
+		ID:               r.ID,
+		Description:      r.Description,
+		Tags:             r.Tags,
+		Quantity:         r.Quantity,
+		Price:            r.Price,
+		TaxClass:         r.TaxClass,
+		ReorderThreshold: r.ReorderThreshold,
+		Version:          r.Version,
+	}
+	if r.SKU != nil {
+		product.SKU = *r.SKU
+	}
+	return product
+}
+
+const productVersionColumns = `product_id, version, description, tags, price, tax_class, reorder_threshold, valid_from`
+
+type productVersionRow struct {
+	ProductID        int64
+	Version          int64
+	Description      string
+	Tags             []string
+	Price            float64
+	TaxClass         string
+	ReorderThreshold *int
+	ValidFrom        time.Time
+}
+
+func scanProductVersionRow(row pgx.Row) (productVersionRow, error) {
+	var r productVersionRow
+	err := row.Scan(&r.ProductID, &r.Version, &r.Description, &r.Tags, &r.Price, &r.TaxClass, &r.ReorderThreshold, &r.ValidFrom)
+	return r, err
+}
+
+func (r productVersionRow) toDomain() domain.ProductVersion {
+	return domain.ProductVersion{
+		ProductID:        r.ProductID,
+		Version:          r.Version,
+		Description:      r.Description,
+		Tags:             r.Tags,
+		Price:            r.Price,
+		TaxClass:         r.TaxClass,
+		ReorderThreshold: r.ReorderThreshold,
+		ValidFrom:        r.ValidFrom,
+	}
+}
+
+// orderColumns selects an order with the code of its promo code, so it
+// needs orders aliased o and promo_codes p left joined.
+const orderColumns = `
+	o.id, o.user_id, o.status, o.created_at, o.cancelled_at,
+	o.subtotal, o.discount, p.code, o.net_total, o.tax_total, o.total_price
+`
+
+type orderRow struct {
+	ID          int64
+	UserID      int64
+	Status      string
+	CreatedAt   time.Time
+	CancelledAt *time.Time
+	Subtotal    float64
+	Discount    float64
+	PromoCode   *string
+	NetTotal    float64
+	TaxTotal    float64
+	TotalPrice  float64
+}
+
+func scanOrderRow(row pgx.Row) (orderRow, error) {
+	var r orderRow
+	err := row.Scan(
+		&r.ID, &r.UserID, &r.Status, &r.CreatedAt, &r.CancelledAt,
+		&r.Subtotal, &r.Discount, &r.PromoCode, &r.NetTotal, &r.TaxTotal, &r.TotalPrice,
+	)
+	return r, err
+}
+
+func (r orderRow) toDomain(lines []orderLineRow) domain.Order {
+	order := domain.Order{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		Status:      r.Status,
+		CreatedAt:   r.CreatedAt,
+		CancelledAt: r.CancelledAt,
+		Subtotal:    r.Subtotal,
+		Discount:    r.Discount,
+		NetTotal:    r.NetTotal,
+		TaxTotal:    r.TaxTotal,
+		TotalPrice:  r.TotalPrice,
+	}
+	if r.PromoCode != nil {
+		order.PromoCode = *r.PromoCode
+	}
+	for _, line := range lines {
+		order.OrderProduct = append(order.OrderProduct, line.toDomain())
+	}
+	return order
+}
+
+const orderLineColumns = `
+	order_id, product_id, product_version, description, tags, quantity,
+	price, discount, tax_class, tax_rate, tax_amount
+`
+
+type orderLineRow struct {
+	OrderID        int64
+	ProductID      int64
+	ProductVersion int64
+	Description    string
+	Tags           []string
+	Quantity       int
+	Price          float64
+	Discount       float64
+	TaxClass       string
+	TaxRate        float64
+	TaxAmount      float64
+}
+
+func scanOrderLineRow(row pgx.Row) (orderLineRow, error) {
+	var r orderLineRow
+	err := row.Scan(
+		&r.OrderID, &r.ProductID, &r.ProductVersion, &r.Description, &r.Tags, &r.Quantity,
+		&r.Price, &r.Discount, &r.TaxClass, &r.TaxRate, &r.TaxAmount,
+	)
+	return r, err
+}
+
+func (r orderLineRow) toDomain() domain.OrderProduct {
+	return domain.OrderProduct{
+		OrderID:        r.OrderID,
+		ProductID:      r.ProductID,
+		ProductVersion: r.ProductVersion,
+		Description:    r.Description,
+		Tags:           r.Tags,
+		Quantity:       r.Quantity,
+		Price:          r.Price,
+		Discount:       r.Discount,
+		TaxClass:       r.TaxClass,
+		TaxRate:        r.TaxRate,
+		TaxAmount:      r.TaxAmount,
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"pet-project/internal/domain"
+)
+
+// fakeRow scans its values into the destinations in order, as pgx would
+// for a row with those column values.
+type fakeRow []any
+
+func (r fakeRow) Scan(dest ...any) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("%d destinations for %d columns", len(dest), len(r))
+	}
+	for i, value := range r {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if value == nil {
+			target.SetZero()
+			continue
+		}
+		v := reflect.ValueOf(value)
+		if target.Kind() == reflect.Pointer {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			v = ptr
+		}
+		target.Set(v)
+	}
+	return nil
+}
+
+func TestUserRowToDomain(t *testing.T) {
+	row, err := scanUserRow(fakeRow{int64(1), "Ivan", "Petrov", "Ivan Petrov", 30, true, int64(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := domain.User{ID: 1, FirstName: "Ivan", LastName: "Petrov", FullName: "Ivan Petrov", Age: 30, IsMarried: true, Version: 2}
+	if got := row.toDomain(); got != want {
+		t.Errorf("toDomain = %+v, want %+v", got, want)
+	}
+}
+
+func TestProductRowToDomain(t *testing.T) {
+	tests := []struct {
+		name string
+		row  fakeRow
+		want domain.Product
+	}{
+		{
+			name: "all columns set",
+			row:  fakeRow{int64(7), "TEA-1", "Tea", []string{"drinks"}, 10, 4.5, "reduced", 3, int64(1)},
+			want: domain.Product{ID: 7, SKU: "TEA-1", Description: "Tea", Tags: []string{"drinks"}, Quantity: 10, Price: 4.5, TaxClass: "reduced", ReorderThreshold: ptr(3), Version: 1},
+		},
+		{
+			name: "null sku and threshold",
+			row:  fakeRow{int64(8), nil, "Coffee", []string{}, 0, 9.0, "standard", nil, int64(4)},
+			want: domain.Product{ID: 8, Description: "Coffee", Tags: []string{}, Price: 9, TaxClass: "standard", Version: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := scanProductRow(tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := row.toDomain(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toDomain = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductVersionRowToDomain(t *testing.T) {
+	validFrom := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	row, err := scanProductVersionRow(fakeRow{int64(7), int64(2), "Tea", []string{"drinks"}, 4.5, "reduced", nil, validFrom})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := domain.ProductVersion{ProductID: 7, Version: 2, Description: "Tea", Tags: []string{"drinks"}, Price: 4.5, TaxClass: "reduced", ValidFrom: validFrom}
+	if got := row.toDomain(); !reflect.DeepEqual(got, want) {
+		t.Errorf("toDomain = %+v, want %+v", got, want)
+	}
+}
+
+func TestOrderRowToDomain(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	order, err := scanOrderRow(fakeRow{int64(5), int64(1), domain.OrderStatusCreated, createdAt, nil, 10.0, 0.0, nil, 10.0, 2.0, 12.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := scanOrderLineRow(fakeRow{int64(5), int64(2), int64(3), "Tea", []string{"drinks"}, 2, 5.0, 0.0, "standard", 0.2, 2.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := domain.Order{
+		ID: 5, UserID: 1, Status: domain.OrderStatusCreated, CreatedAt: createdAt,
+		Subtotal: 10, NetTotal: 10, TaxTotal: 2, TotalPrice: 12,
+		OrderProduct: []domain.OrderProduct{{
+			OrderID: 5, ProductID: 2, ProductVersion: 3, Description: "Tea", Tags: []string{"drinks"},
+			Quantity: 2, Price: 5, TaxClass: "standard", TaxRate: 0.2, TaxAmount: 2,
+		}},
+	}
+	if got := order.toDomain([]orderLineRow{line}); !reflect.DeepEqual(got, want) {
+		t.Errorf("toDomain = %+v, want %+v", got, want)
+	}
+
+	order.PromoCode = ptr("SPRING10")
+	if got := order.toDomain(nil).PromoCode; got != "SPRING10" {
+		t.Errorf("PromoCode = %q, want SPRING10", got)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// recordMovement appends a ledger row. It must run in the transaction that
+// changed products.quantity so the two cannot diverge.
+func recordMovement(ctx context.Context, q querier, movement domain.StockMovement) error {
+	query := `
+		INSERT INTO stock_movements (product_id, kind, delta, quantity_after, reason, order_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := q.Exec(ctx, query,
+		movement.ProductID,
+		movement.Kind,
+		movement.Delta,
+		movement.QuantityAfter,
+		movement.Reason,
+		movement.OrderID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record %s movement for product %d: %w", movement.Kind, movement.ProductID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) ListStockMovements(ctx context.Context, productID, before int64, limit int) ([]domain.StockMovement, error) {
+	s.logger.Info("Listing stock movements", "product_id", productID, "before", before, "limit", limit)
+	db := s.reader(ctx)
+
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)`, productID).Scan(&exists); err != nil {
+		s.logger.Error(err, "Failed to check product", "product_id", productID)
+		return nil, fmt.Errorf("failed to check product %d: %w", productID, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found: %w", domain.ErrNotFound)
+	}
+
+	query := `
+		SELECT id, product_id, kind, delta, quantity_after, reason, order_id, created_at
+		FROM stock_movements
+		WHERE product_id = $1 AND ($2::bigint = 0 OR id < $2::bigint)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	rows, err := db.Query(ctx, query, productID, before, limit)
+	if err != nil {
+		s.logger.Error(err, "Failed to list stock movements", "product_id", productID)
+		return nil, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := make([]domain.StockMovement, 0, limit)
+	for rows.Next() {
+		var m domain.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.Kind, &m.Delta, &m.QuantityAfter, &m.Reason, &m.OrderID, &m.CreatedAt); err != nil {
+			s.logger.Error(err, "Failed to scan stock movement", "product_id", productID)
+			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(err, "Failed to iterate stock movements", "product_id", productID)
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	s.logger.Info("Stock movements listed", "product_id", productID, "count", len(movements))
+	return movements, nil
+}
+
+func (s *PostgresStorage) ReconcileStock(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	s.logger.Info("Reconciling stock ledger")
+	query := `
+		SELECT p.id, p.quantity, COALESCE(SUM(m.delta), 0) AS ledger_total
+		FROM products p
+		LEFT JOIN stock_movements m ON m.product_id = p.id
+		GROUP BY p.id, p.quantity
+		HAVING p.quantity <> COALESCE(SUM(m.delta), 0)
+		ORDER BY p.id
+	`
+	rows, err := s.reader(ctx).Query(ctx, query)
+	if err != nil {
+		s.logger.Error(err, "Failed to reconcile stock")
+		return nil, fmt.Errorf("failed to reconcile stock: %w", err)
+	}
+	defer rows.Close()
+
+	discrepancies := []domain.StockDiscrepancy{}
+	for rows.Next() {
+		var d domain.StockDiscrepancy
+		if err := rows.Scan(&d.ProductID, &d.Quantity, &d.LedgerTotal); err != nil {
+			s.logger.Error(err, "Failed to scan stock discrepancy")
+			return nil, fmt.Errorf("failed to scan stock discrepancy: %w", err)
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(err, "Failed to iterate stock discrepancies")
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	s.logger.Info("Stock ledger reconciled", "discrepancies", len(discrepancies))
+	return discrepancies, nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"fmt"
+
+	"pet-project/internal/domain"
+)
+
+func (s *PostgresStorage) taxRate(class string) (float64, error) {
+	rate, ok := s.tax.Classes[class]
+	if !ok {
+		return 0, fmt.Errorf("tax class %q: %w", class, domain.ErrUnknownTaxClass)
+	}
+	return rate, nil
+}
+
+// priceLines spreads discount over the eligible lines in proportion to
+// their amounts, the last one taking the rounding remainder, and fills in
+// the tax of every line on what is left of it. It returns the net and tax
+// totals of the order.
+func (s *PostgresStorage) priceLines(lines []domain.OrderProduct, eligible []bool, discount float64) (float64, float64) {
+	var eligibleTotal float64
+	last := -1
+	for i, line := range lines {
+		if eligible[i] {
+			eligibleTotal += line.Price * float64(line.Quantity)
+			last = i
+		}
+	}
+
+	remaining := discount
+	var netTotal, taxTotal float64
+	for i := range lines {
+		line := &lines[i]
+		amount := line.Price * float64(line.Quantity)
+		switch {
+		case i == last:
+			line.Discount = roundMoney(remaining)
+		case eligible[i]:
+			line.Discount = roundMoney(discount * amount / eligibleTotal)
+			remaining -= line.Discount
+		}
+
+		amount -= line.Discount
+		if s.tax.PricesIncludeTax {
+			line.TaxAmount = roundMoney(amount * line.TaxRate / (1 + line.TaxRate))
+			netTotal += amount - line.TaxAmount
+		} else {
+			line.TaxAmount = roundMoney(amount * line.TaxRate)
+			netTotal += amount
+		}
+		taxTotal += line.TaxAmount
+	}
+	return roundMoney(netTotal), roundMoney(taxTotal)
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ErrCodeSerializationFailure = "40001"
+	ErrCodeDeadlockDetected     = "40P01"
+)
+
+const txRetryDelay = 20 * time.Millisecond
+
+type IsolationLevel string
+
+const (
+	ReadCommitted  IsolationLevel = "read committed"
+	RepeatableRead IsolationLevel = "repeatable read"
+	Serializable   IsolationLevel = "serializable"
+)
+
+type isolationKey struct{}
+
+// WithIsolation makes a unit of work started with ctx run at level instead
+// of the configured default.
+func WithIsolation(ctx context.Context, level IsolationLevel) context.Context {
+	return context.WithValue(ctx, isolationKey{}, level)
+}
+
+// unitOfWork is the transaction WithinTx carries in the context, with the
+// work to do once it commits.
+type unitOfWork struct {
+	tx          pgx.Tx
+	afterCommit []func()
+}
+
+type unitOfWorkKey struct{}
+
+func unitOfWorkFrom(ctx context.Context) *unitOfWork {
+	uow, _ := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	return uow
+}
+
+// WithinTx runs fn in one transaction: every repository call made with the
+// context fn receives joins it, and they all commit or roll back together.
+// fn is run again from the start when the transaction fails on a
+// serialization failure or deadlock, so it must not have effects outside
+// the database; defer those with AfterCommit. A WithinTx inside another
+// joins the outer transaction.
+func (s *PostgresStorage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if unitOfWorkFrom(ctx) != nil {
+		return fn(ctx)
+	}
+
+	isolation, ok := ctx.Value(isolationKey{}).(IsolationLevel)
+	if !ok {
+		isolation = s.isolation
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := s.runTx(ctx, isolation, fn)
+		if err == nil || !retryable(err) || attempt >= s.txMaxAttempts {
+			return err
+		}
+
+		delay := txRetryDelay*time.Duration(attempt) + rand.N(txRetryDelay)
+		s.logger.Info("Retrying transaction", "attempt", attempt, "delay", delay, "reason", err.Error())
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *PostgresStorage) runTx(ctx context.Context, isolation IsolationLevel, fn func(ctx context.Context) error) error {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(isolation)})
+	if err != nil {
+		s.logger.Error(err, "Failed to start transaction")
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	uow := &unitOfWork{tx: tx}
+	if err := fn(context.WithValue(ctx, unitOfWorkKey{}, uow)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(err, "Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, f := range uow.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// AfterCommit runs f once the unit of work ctx carries has committed, and
+// not at all if it rolls back. Outside a unit of work f runs at once.
+func (s *PostgresStorage) AfterCommit(ctx context.Context, f func()) {
+	if uow := unitOfWorkFrom(ctx); uow != nil {
+		uow.afterCommit = append(uow.afterCommit, f)
+		return
+	}
+	f()
+}
+
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		(pgErr.Code == ErrCodeSerializationFailure || pgErr.Code == ErrCodeDeadlockDetected)
+}
+
+// db is what a statement runs on: the unit of work's transaction when ctx
+// carries one, otherwise the primary.
+func (s *PostgresStorage) db(ctx context.Context) querier {
+	if uow := unitOfWorkFrom(ctx); uow != nil {
+		return uow.tx
+	}
+	return s.pool
+}
+
+// begin starts a transaction on the primary. Inside a unit of work it
+// starts a savepoint instead, so the caller's commit and rollback apply to
+// its own statements and the unit of work decides the outcome.
+func (s *PostgresStorage) begin(ctx context.Context) (pgx.Tx, error) {
+	return s.beginOn(ctx, s.pool, pgx.TxOptions{})
+}
+
+func (s *PostgresStorage) beginOn(ctx context.Context, pool *pgxpool.Pool, opts pgx.TxOptions) (pgx.Tx, error) {
+	if uow := unitOfWorkFrom(ctx); uow != nil {
+		return uow.tx.Begin(ctx)
+	}
+	return pool.BeginTx(ctx, opts)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pet-project/internal/domain"
+)
+
+var userSortColumns = map[string]string{
+	"id":        "id",
+	"full_name": "full_name",
+	"age":       "age",
+}
+
+func (s *PostgresStorage) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	s.logger.Info("Listing users", "search", filter.Search, "sort", filter.SortBy, "limit", filter.Limit)
+
+	column, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort column %q", filter.SortBy)
+	}
+
+	where := []string{"deleted_at IS NULL"}
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Search != "" {
+		where = append(where, "full_name ILIKE "+arg("%"+escapeLike(filter.Search)+"%"))
+	}
+	if filter.MinAge != nil {
+		where = append(where, "age >= "+arg(*filter.MinAge))
+	}
+	if filter.MaxAge != nil {
+		where = append(where, "age <= "+arg(*filter.MaxAge))
+	}
+	if filter.IsMarried != nil {
+		where = append(where, "is_married = "+arg(*filter.IsMarried))
+	}
+
+	cmp, dir := ">", "ASC"
+	if filter.SortDesc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if after := filter.After; after != nil {
+		switch column {
+		case "id":
+			where = append(where, "id "+cmp+" "+arg(after.ID))
+		case "full_name":
+			where = append(where, fmt.Sprintf("(full_name, id) %s (%s, %s)", cmp, arg(after.FullName), arg(after.ID)))
+		case "age":
+			where = append(where, fmt.Sprintf("(age, id) %s (%s, %s)", cmp, arg(after.Age), arg(after.ID)))
+		}
+	}
+
+	order := fmt.Sprintf("%s %s", column, dir)
+	if column != "id" {
+		order += fmt.Sprintf(", id %s", dir)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		WHERE %s
+		ORDER BY %s
+		LIMIT %s
+	`, userColumns, strings.Join(where, " AND "), order, arg(filter.Limit))
+
+	rows, err := s.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error(err, "Failed to list users")
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]domain.User, 0, filter.Limit)
+	for rows.Next() {
+		row, err := scanUserRow(rows)
+		if err != nil {
+			s.logger.Error(err, "Failed to scan user")
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, row.toDomain())
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(err, "Failed to iterate users")
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	s.logger.Info("Users listed", "count", len(users))
+	return users, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
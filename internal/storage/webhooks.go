@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pet-project/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *PostgresStorage) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	s.logger.Info("Creating webhook subscription", "url", sub.URL, "event_types", sub.EventTypes)
+	query := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := s.db(ctx).QueryRow(ctx, query, sub.URL, sub.EventTypes, sub.Secret).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		s.logger.Error(err, "Failed to create webhook subscription", "url", sub.URL)
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	s.logger.Info("Webhook subscription created", "id", sub.ID)
+	return sub, nil
+}
+
+// ListWebhookSubscriptions returns active subscriptions without their secrets.
+func (s *PostgresStorage) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	s.logger.Info("Listing webhook subscriptions")
+	query := `
+		SELECT id, url, event_types, created_at
+		FROM webhook_subscriptions
+		WHERE deleted_at IS NULL
+		ORDER BY id
+	`
+	rows, err := s.db(ctx).Query(ctx, query)
+	if err != nil {
+		s.logger.Error(err, "Failed to list webhook subscriptions")
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.WebhookSubscription, error) {
+		var sub domain.WebhookSubscription
+		err := row.Scan(&sub.ID, &sub.URL, &sub.EventTypes, &sub.CreatedAt)
+		return sub, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan webhook subscriptions")
+		return nil, fmt.Errorf("failed to scan webhook subscriptions: %w", err)
+	}
+
+	s.logger.Info("Webhook subscriptions listed", "count", len(subs))
+	return subs, nil
+}
+
+func (s *PostgresStorage) GetWebhookSubscription(ctx context.Context, id int64) (domain.WebhookSubscription, error) {
+	s.logger.Info("Fetching webhook subscription", "id", id)
+	query := `
+		SELECT id, url, event_types, created_at
+		FROM webhook_subscriptions
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	var sub domain.WebhookSubscription
+	err := s.db(ctx).QueryRow(ctx, query, id).Scan(&sub.ID, &sub.URL, &sub.EventTypes, &sub.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WebhookSubscription{}, fmt.Errorf("webhook subscription not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to get webhook subscription", "id", id)
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	s.logger.Info("Webhook subscription fetched", "id", id)
+	return sub, nil
+}
+
+// DeleteWebhookSubscription deactivates the subscription. Its delivery log is
+// kept, but pending deliveries are no longer attempted.
+func (s *PostgresStorage) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	s.logger.Info("Deleting webhook subscription", "id", id)
+	query := `
+		UPDATE webhook_subscriptions
+		SET deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	tag, err := s.db(ctx).Exec(ctx, query, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to delete webhook subscription", "id", id)
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("webhook subscription not found: %w", domain.ErrNotFound)
+	}
+
+	s.logger.Info("Webhook subscription deleted", "id", id)
+	return nil
+}
+
+// EnqueueWebhookDeliveries creates a pending delivery of event for every
+// subscription to its type. It is idempotent, so the outbox may retry it.
+func (s *PostgresStorage) EnqueueWebhookDeliveries(ctx context.Context, event domain.Event) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type)
+		SELECT id, $1, $2
+		FROM webhook_subscriptions
+		WHERE deleted_at IS NULL AND $2 = ANY (event_types)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+	tag, err := s.db(ctx).Exec(ctx, query, event.ID, event.Type)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries for event %d: %w", event.ID, err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimWebhookDeliveries leases up to limit due deliveries by moving their
+// next attempt to the end of the lease, so a worker that dies mid-delivery
+// only delays them. The HTTP calls then happen outside any transaction.
+func (s *PostgresStorage) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = now() + $2::interval
+			WHERE id IN (
+				SELECT d.id
+				FROM webhook_deliveries d
+				JOIN webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE d.status = 'pending'
+					AND d.next_attempt_at <= now()
+					AND s.deleted_at IS NULL
+				ORDER BY d.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING id, subscription_id, event_id, event_type, status, attempts, next_attempt_at, created_at
+		)
+		SELECT c.id, c.subscription_id, c.event_id, c.event_type, c.status, c.attempts, c.next_attempt_at, c.created_at,
+			s.url, s.secret, e.aggregate_type, e.aggregate_id, e.payload, e.created_at
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		JOIN outbox_events e ON e.id = c.event_id
+		ORDER BY c.id
+	`
+	rows, err := s.db(ctx).Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.WebhookDelivery, error) {
+		var (
+			d   domain.WebhookDelivery
+			req domain.WebhookRequest
+		)
+		err := row.Scan(
+			&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt,
+			&req.URL, &req.Secret, &req.Event.AggregateType, &req.Event.AggregateID, &req.Event.Payload, &req.Event.CreatedAt,
+		)
+		req.Event.ID = d.EventID
+		req.Event.Type = d.EventType
+		d.Request = &req
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt appends attempt to the delivery log and moves the
+// delivery to status. A pending delivery is retried after retryIn.
+func (s *PostgresStorage) RecordWebhookAttempt(ctx context.Context, deliveryID int64, attempt domain.WebhookAttempt, status string, retryIn time.Duration) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var lastError *string
+	if attempt.Error != "" {
+		lastError = &attempt.Error
+	}
+
+	query := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, response_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = tx.Exec(ctx, query, deliveryID, attempt.AttemptedAt, attempt.ResponseCode, lastError, attempt.DurationMS)
+	if err != nil {
+		return fmt.Errorf("failed to record attempt of webhook delivery %d: %w", deliveryID, err)
+	}
+
+	query = `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			attempts = attempts + 1,
+			response_code = $3,
+			last_error = $4,
+			last_attempt_at = $5,
+			next_attempt_at = now() + $6::interval
+		WHERE id = $1
+	`
+	_, err = tx.Exec(ctx, query, deliveryID, status, attempt.ResponseCode, lastError, attempt.AttemptedAt, retryIn)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery %d: %w", deliveryID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `
+	id, subscription_id, event_id, event_type, status, attempts, response_code,
+	last_error, next_attempt_at, last_attempt_at, created_at
+`
+
+func scanWebhookDelivery(row pgx.Row) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := row.Scan(
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.ResponseCode,
+		&d.LastError, &d.NextAttemptAt, &d.LastAttemptAt, &d.CreatedAt,
+	)
+	return d, err
+}
+
+// ListWebhookDeliveries returns deliveries of a subscription, newest first,
+// with IDs below before when it is non-zero.
+func (s *PostgresStorage) ListWebhookDeliveries(ctx context.Context, subscriptionID, before int64, limit int) ([]domain.WebhookDelivery, error) {
+	s.logger.Info("Listing webhook deliveries", "subscription_id", subscriptionID, "before", before, "limit", limit)
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2::bigint = 0 OR id < $2::bigint)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	rows, err := s.db(ctx).Query(ctx, query, subscriptionID, before, limit)
+	if err != nil {
+		s.logger.Error(err, "Failed to list webhook deliveries", "subscription_id", subscriptionID)
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.WebhookDelivery, error) {
+		return scanWebhookDelivery(row)
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan webhook deliveries", "subscription_id", subscriptionID)
+		return nil, fmt.Errorf("failed to scan webhook deliveries: %w", err)
+	}
+
+	s.logger.Info("Webhook deliveries listed", "subscription_id", subscriptionID, "count", len(deliveries))
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery together with its attempt log.
+func (s *PostgresStorage) GetWebhookDelivery(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error) {
+	s.logger.Info("Fetching webhook delivery", "subscription_id", subscriptionID, "id", id)
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = $1 AND subscription_id = $2
+	`
+	delivery, err := scanWebhookDelivery(s.db(ctx).QueryRow(ctx, query, id, subscriptionID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WebhookDelivery{}, fmt.Errorf("webhook delivery not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to get webhook delivery", "id", id)
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	query = `
+		SELECT attempted_at, response_code, COALESCE(error, ''), duration_ms
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id
+	`
+	rows, err := s.db(ctx).Query(ctx, query, id)
+	if err != nil {
+		s.logger.Error(err, "Failed to get webhook delivery attempts", "id", id)
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to get webhook delivery attempts: %w", err)
+	}
+	delivery.Log, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.WebhookAttempt, error) {
+		var a domain.WebhookAttempt
+		err := row.Scan(&a.AttemptedAt, &a.ResponseCode, &a.Error, &a.DurationMS)
+		return a, err
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to scan webhook delivery attempts", "id", id)
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to scan webhook delivery attempts: %w", err)
+	}
+
+	s.logger.Info("Webhook delivery fetched", "id", id)
+	return delivery, nil
+}
+
+// RedeliverWebhook puts a delivery back in the queue for immediate delivery
+// with a fresh attempt budget. Earlier attempts stay in the log.
+func (s *PostgresStorage) RedeliverWebhook(ctx context.Context, subscriptionID, id int64) (domain.WebhookDelivery, error) {
+	s.logger.Info("Redelivering webhook", "subscription_id", subscriptionID, "id", id)
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = now()
+		FROM webhook_subscriptions s
+		WHERE d.id = $1
+			AND d.subscription_id = $2
+			AND s.id = d.subscription_id
+			AND s.deleted_at IS NULL
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.status, d.attempts, d.response_code,
+			d.last_error, d.next_attempt_at, d.last_attempt_at, d.created_at
+	`
+	delivery, err := scanWebhookDelivery(s.db(ctx).QueryRow(ctx, query, id, subscriptionID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WebhookDelivery{}, fmt.Errorf("webhook delivery not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to redeliver webhook", "id", id)
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	s.logger.Info("Webhook queued for redelivery", "id", id)
+	return delivery, nil
+}
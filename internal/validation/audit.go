@@ -0,0 +1,24 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+)
+
+func ValidateAuditFilter(filter domain.AuditFilter) error {
+	switch filter.Entity {
+	case "", domain.AggregateUser, domain.AggregateProduct, domain.AggregateOrder:
+	default:
+		return errors.Join(service.ErrValidation, fmt.Errorf("entity must be one of %s, %s, %s", domain.AggregateUser, domain.AggregateProduct, domain.AggregateOrder))
+	}
+	if filter.EntityID != nil && filter.Entity == "" {
+		return errors.Join(service.ErrValidation, errors.New("entity_id requires entity"))
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return errors.Join(service.ErrValidation, errors.New("from must be before to"))
+	}
+	return ValidateLimit(filter.Limit)
+}
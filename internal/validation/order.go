@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+)
+
+func ValidateCreateOrder(order domain.NewOrder) error {
+	if order.UserID <= 0 {
+		return errors.Join(service.ErrValidation, errors.New("user_id is required"))
+	}
+	if len(order.Items) == 0 {
+		return errors.Join(service.ErrValidation, errors.New("order must contain at least one item"))
+	}
+
+	seen := make(map[int64]bool, len(order.Items))
+	for _, item := range order.Items {
+		if item.ProductID <= 0 {
+			return errors.Join(service.ErrValidation, errors.New("product_id is required"))
+		}
+		if item.Quantity <= 0 {
+			return errors.Join(service.ErrValidation, fmt.Errorf("quantity for product %d must be positive", item.ProductID))
+		}
+		if seen[item.ProductID] {
+			return errors.Join(service.ErrValidation, fmt.Errorf("product %d is listed more than once", item.ProductID))
+		}
+		seen[item.ProductID] = true
+	}
+	if len(order.PromoCode) > maxPromoCodeLength {
+		return errors.Join(service.ErrValidation, errors.New("promo_code is too long"))
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package validation
+
+import (
+	"errors"
+
+	"pet-project/internal/service"
+)
+
+func ValidateLimit(limit int) error {
+	if limit < 1 || limit > 100 {
+		return errors.Join(service.ErrValidation, errors.New("limit must be between 1 and 100"))
+	}
+	return nil
+}
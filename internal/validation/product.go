@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"errors"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+)
+
+func ValidateCreateProduct(product domain.Product) error {
+	if product.Description == "" {
+		return errors.Join(service.ErrValidation, errors.New("description is required"))
+	}
+	if product.Quantity < 0 {
+		return errors.Join(service.ErrValidation, errors.New("quantity cannot be negative"))
+	}
+	if product.Price < 0 {
+		return errors.Join(service.ErrValidation, errors.New("price cannot be negative"))
+	}
+	if product.ReorderThreshold != nil && *product.ReorderThreshold < 0 {
+		return errors.Join(service.ErrValidation, errors.New("reorder_threshold cannot be negative"))
+	}
+	return nil
+}
+
+func ValidateUpdateProduct(update domain.ProductUpdate) error {
+	if update.Description == nil && update.Tags == nil && update.Price == nil && update.ReorderThreshold == nil && update.TaxClass == nil {
+		return errors.Join(service.ErrValidation, errors.New("at least one of description, tags, price, reorder_threshold, tax_class is required"))
+	}
+	if update.Description != nil && *update.Description == "" {
+		return errors.Join(service.ErrValidation, errors.New("description cannot be empty"))
+	}
+	if update.Price != nil && *update.Price < 0 {
+		return errors.Join(service.ErrValidation, errors.New("price cannot be negative"))
+	}
+	if update.ReorderThreshold != nil && *update.ReorderThreshold < 0 {
+		return errors.Join(service.ErrValidation, errors.New("reorder_threshold cannot be negative"))
+	}
+	return nil
+}
+
+func ValidateStockAdjustment(adjustment domain.StockAdjustment) error {
+	if adjustment.Delta == 0 {
+		return errors.Join(service.ErrValidation, errors.New("delta cannot be zero"))
+	}
+	if adjustment.Reason == "" {
+		return errors.Join(service.ErrValidation, errors.New("reason is required"))
+	}
+	switch adjustment.Kind {
+	case "", domain.MovementCorrection:
+	case domain.MovementRestock:
+		if adjustment.Delta < 0 {
+			return errors.Join(service.ErrValidation, errors.New("restock delta must be positive"))
+		}
+	default:
+		return errors.Join(service.ErrValidation, errors.New("kind must be restock or correction"))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+)
+
+const maxPromoCodeLength = 64
+
+func ValidateCreatePromoCode(promo domain.PromoCode) error {
+	if promo.Code == "" || len(promo.Code) > maxPromoCodeLength || strings.ContainsFunc(promo.Code, unicode.IsSpace) {
+		return errors.Join(service.ErrValidation, errors.New("code is required and must be up to 64 characters without spaces"))
+	}
+	switch promo.Kind {
+	case domain.PromoPercent:
+		if promo.Value <= 0 || promo.Value > 100 {
+			return errors.Join(service.ErrValidation, errors.New("percent value must be in (0, 100]"))
+		}
+	case domain.PromoFixed:
+		if promo.Value <= 0 {
+			return errors.Join(service.ErrValidation, errors.New("fixed value must be positive"))
+		}
+	default:
+		return errors.Join(service.ErrValidation, errors.New("kind must be percent or fixed"))
+	}
+	if promo.MinOrderValue < 0 {
+		return errors.Join(service.ErrValidation, errors.New("min_order_value cannot be negative"))
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && !promo.ValidFrom.Before(*promo.ValidUntil) {
+		return errors.Join(service.ErrValidation, errors.New("valid_from must be before valid_until"))
+	}
+	if promo.MaxUses != nil && *promo.MaxUses <= 0 {
+		return errors.Join(service.ErrValidation, errors.New("max_uses must be positive"))
+	}
+	if promo.MaxUsesPerUser != nil && *promo.MaxUsesPerUser <= 0 {
+		return errors.Join(service.ErrValidation, errors.New("max_uses_per_user must be positive"))
+	}
+	for _, id := range promo.ProductIDs {
+		if id <= 0 {
+			return errors.Join(service.ErrValidation, errors.New("product_ids must be positive"))
+		}
+	}
+	return nil
+}
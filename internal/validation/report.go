@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+)
+
+// maxReportPeriods keeps a revenue report to a size worth reading.
+const maxReportPeriods = 1000
+
+var reportPeriodLengths = map[string]time.Duration{
+	domain.ReportPeriodDay:   24 * time.Hour,
+	domain.ReportPeriodWeek:  7 * 24 * time.Hour,
+	domain.ReportPeriodMonth: 28 * 24 * time.Hour,
+}
+
+func ValidateTimeRange(from, to *time.Time) error {
+	if from == nil || to == nil {
+		return errors.Join(service.ErrValidation, errors.New("from and to are required"))
+	}
+	if !from.Before(*to) {
+		return errors.Join(service.ErrValidation, errors.New("from must be before to"))
+	}
+	return nil
+}
+
+func ValidateRevenueReport(filter domain.ReportFilter) error {
+	if !slices.Contains(domain.ReportPeriods, filter.Period) {
+		return errors.Join(service.ErrValidation, fmt.Errorf("period must be one of %s", strings.Join(domain.ReportPeriods, ", ")))
+	}
+	if _, err := time.LoadLocation(filter.TimeZone); err != nil || filter.TimeZone == "" || filter.TimeZone == "Local" {
+		return errors.Join(service.ErrValidation, fmt.Errorf("unknown time zone %q", filter.TimeZone))
+	}
+	if filter.To.Sub(filter.From)/reportPeriodLengths[filter.Period] > maxReportPeriods {
+		return errors.Join(service.ErrValidation, fmt.Errorf("the range spans more than %d periods, use a longer period", maxReportPeriods))
+	}
+	return nil
+}
@@ -2,8 +2,6 @@ package validation
 
 import (
 	"errors"
-	"strconv"
-	"strings"
 
 	"pet-project/internal/domain"
 	"pet-project/internal/service"
@@ -22,16 +20,30 @@ func ValidateCreateUser(user domain.User) error {
 	return nil
 }
 
-func ValidateUserID(path string) (int64, error) {
-	parts := strings.Split(path, "/")
-	if len(parts) != 3 || parts[1] != "users" {
-		return 0, errors.Join(service.ErrValidation, errors.New("invalid URL path"))
+func ValidateUpdateUser(update domain.UserUpdate) error {
+	if update.FirstName == nil && update.LastName == nil && update.IsMarried == nil {
+		return errors.Join(service.ErrValidation, errors.New("at least one of first_name, last_name, is_married is required"))
 	}
+	if update.FirstName != nil && *update.FirstName == "" {
+		return errors.Join(service.ErrValidation, errors.New("first_name cannot be empty"))
+	}
+	if update.LastName != nil && *update.LastName == "" {
+		return errors.Join(service.ErrValidation, errors.New("last_name cannot be empty"))
+	}
+	return nil
+}
 
-	idStr := parts[2]
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		return 0, errors.Join(service.ErrValidation, errors.New("invalid user ID"))
+func ValidateUserFilter(filter domain.UserFilter) error {
+	switch filter.SortBy {
+	case "id", "full_name", "age":
+	default:
+		return errors.Join(service.ErrValidation, errors.New("sort must be one of id, full_name, age"))
 	}
-	return id, nil
+	if err := ValidateLimit(filter.Limit); err != nil {
+		return err
+	}
+	if filter.MinAge != nil && filter.MaxAge != nil && *filter.MinAge > *filter.MaxAge {
+		return errors.Join(service.ErrValidation, errors.New("min_age cannot be greater than max_age"))
+	}
+	return nil
 }
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"pet-project/internal/domain"
+	"pet-project/internal/service"
+	"pet-project/internal/webhook"
+)
+
+const minWebhookSecretLength = 16
+
+func ValidateCreateWebhook(sub domain.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Join(service.ErrValidation, errors.New("url must be an absolute http or https URL"))
+	}
+	if !webhook.PublicHost(u.Hostname()) {
+		return errors.Join(service.ErrValidation, errors.New("url must not point at a loopback, link-local or private address"))
+	}
+	if len(sub.EventTypes) == 0 {
+		return errors.Join(service.ErrValidation, errors.New("event_types must contain at least one event type"))
+	}
+
+	seen := make(map[string]bool, len(sub.EventTypes))
+	for _, eventType := range sub.EventTypes {
+		if !slices.Contains(domain.EventTypes, eventType) {
+			return errors.Join(service.ErrValidation, fmt.Errorf("unknown event type %q", eventType))
+		}
+		if seen[eventType] {
+			return errors.Join(service.ErrValidation, fmt.Errorf("event type %q is listed more than once", eventType))
+		}
+		seen[eventType] = true
+	}
+
+	if sub.Secret != "" && len(sub.Secret) < minWebhookSecretLength {
+		return errors.Join(service.ErrValidation, fmt.Errorf("secret must be at least %d characters", minWebhookSecretLength))
+	}
+	return nil
+}
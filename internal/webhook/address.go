@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for subscriber addresses inside the
+// network the service runs in.
+var ErrForbiddenAddress = errors.New("address is loopback, link-local or private")
+
+// blockedPrefixes are ranges the netip predicates in PublicAddress miss:
+// "this network", which Linux routes to the host itself, and carrier-grade
+// NAT space.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// PublicAddress reports whether a subscriber may be reached at addr.
+// Subscribers are given by API callers, so without this check they could
+// point deliveries, signed by the service, at its own network.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// PublicHost reports whether a subscriber URL host, without its port, may
+// be used. IP literals are checked directly; names are checked again once
+// resolved, at delivery time, since what they resolve to can change.
+func PublicHost(host string) bool {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return PublicAddress(addr)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// dialControl refuses connections to addresses PublicAddress rejects. It
+// runs after name resolution, for every address dialled, redirects
+// included, so a name that resolves to an internal address is caught.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse dialled address %q: %w", address, err)
+	}
+	if !PublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// newClient returns the client deliveries are sent with. It does not use a
+// proxy from the environment, which would be dialled instead of the
+// subscriber and so defeat the address check.
+func newClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialControl,
+	}).DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPublicHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"partner.example", true},
+		{"203.0.113.7", true},
+		{"2001:db8::1", true},
+		{"localhost", false},
+		{"api.LOCALHOST.", false},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := PublicHost(tt.host); got != tt.want {
+			t.Errorf("PublicHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	resp, err := newClient(time.Second).Post(srv.URL, "application/json", nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("POST to %s: err = %v, want %v", srv.URL, err, ErrForbiddenAddress)
+	}
+	if called {
+		t.Error("the request reached the loopback server")
+	}
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+	"pet-project/internal/outbox"
+)
+
+type Store interface {
+	EnqueueWebhookDeliveries(ctx context.Context, event domain.Event) (int, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, deliveryID int64, attempt domain.WebhookAttempt, status string, retryIn time.Duration) error
+}
+
+// FanoutSink turns every outbox event into one delivery per subscriber.
+type FanoutSink struct {
+	store Store
+}
+
+func NewFanoutSink(store Store) *FanoutSink {
+	return &FanoutSink{store: store}
+}
+
+func (s *FanoutSink) Publish(ctx context.Context, event domain.Event) error {
+	_, err := s.store.EnqueueWebhookDeliveries(ctx, event)
+	return err
+}
+
+var _ outbox.Sink = (*FanoutSink)(nil)
+
+type Deliverer struct {
+	store  Store
+	client *http.Client
+	cfg    config.Webhooks
+	logger *logger.Logger
+}
+
+func NewDeliverer(store Store, cfg config.Webhooks, logger *logger.Logger) *Deliverer {
+	return &Deliverer{
+		store:  store,
+		client: newClient(cfg.Timeout),
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled. A full batch is followed
+// immediately by the next one so a backlog drains quickly.
+func (d *Deliverer) Run(ctx context.Context) {
+	d.logger.Info("Starting webhook deliverer", "poll_interval", d.cfg.PollInterval, "batch_size", d.cfg.BatchSize)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Webhook deliverer stopped")
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := d.deliverBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Error(err, "Failed to deliver webhooks")
+		}
+
+		next := d.cfg.PollInterval
+		if err == nil && claimed == d.cfg.BatchSize {
+			next = 0
+		}
+		timer.Reset(next)
+	}
+}
+
+// deliverBatch sends a batch concurrently; subscribers are independent, so
+// one slow endpoint must not hold up the others.
+func (d *Deliverer) deliverBatch(ctx context.Context) (int, error) {
+	// The lease outlives the HTTP timeout so a delivery in flight is not
+	// claimed a second time.
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, d.cfg.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *Deliverer) deliver(ctx context.Context, delivery domain.WebhookDelivery) {
+	attempt := d.send(ctx, delivery)
+	attempts := delivery.Attempts + 1
+
+	status := domain.DeliveryPending
+	var retryIn time.Duration
+	switch {
+	case attempt.Error == "":
+		status = domain.DeliverySucceeded
+	case attempts >= d.cfg.MaxAttempts:
+		status = domain.DeliveryFailed
+		d.logger.Error(nil, "Webhook delivery gave up", "id", delivery.ID, "attempts", attempts, "error", attempt.Error)
+	default:
+		retryIn = outbox.Backoff(d.cfg.InitialBackoff, d.cfg.MaxBackoff, attempts)
+		d.logger.Error(nil, "Webhook delivery failed", "id", delivery.ID, "attempts", attempts, "retry_in", retryIn, "error", attempt.Error)
+	}
+
+	if err := d.store.RecordWebhookAttempt(ctx, delivery.ID, attempt, status, retryIn); err != nil && ctx.Err() == nil {
+		d.logger.Error(err, "Failed to record webhook attempt", "id", delivery.ID)
+	}
+}
+
+func (d *Deliverer) send(ctx context.Context, delivery domain.WebhookDelivery) domain.WebhookAttempt {
+	started := time.Now()
+	attempt := domain.WebhookAttempt{AttemptedAt: started}
+	fail := func(err error) domain.WebhookAttempt {
+		attempt.Error = err.Error()
+		attempt.DurationMS = int(time.Since(started).Milliseconds())
+		return attempt
+	}
+
+	req := delivery.Request
+	body, err := json.Marshal(Envelope{
+		ID:            req.Event.ID,
+		Type:          req.Event.Type,
+		AggregateType: req.Event.AggregateType,
+		AggregateID:   req.Event.AggregateID,
+		CreatedAt:     req.Event.CreatedAt,
+		Data:          req.Event.Payload,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to encode event: %w", err))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(body))
+	if err != nil {
+		return fail(fmt.Errorf("failed to build request: %w", err))
+	}
+	timestamp := started.Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderID, strconv.FormatInt(delivery.ID, 10))
+	httpReq.Header.Set(HeaderEvent, req.Event.Type)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, body))
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		return fail(err)
+	}
+	// Drain what is left of the body, up to a limit, so the connection can
+	// be reused for the next delivery instead of being torn down.
+	defer func() {
+		io.CopyN(io.Discard, resp.Body, 64<<10)
+		resp.Body.Close()
+	}()
+
+	attempt.ResponseCode = &resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fail(fmt.Errorf("subscriber responded with status %d", resp.StatusCode))
+	}
+	attempt.DurationMS = int(time.Since(started).Milliseconds())
+	return attempt
+}
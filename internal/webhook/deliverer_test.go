@@ -0,0 +1,297 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pet-project/internal/config"
+	"pet-project/internal/domain"
+	"pet-project/internal/logger"
+)
+
+type recordedAttempt struct {
+	deliveryID int64
+	attempt    domain.WebhookAttempt
+	status     string
+	retryIn    time.Duration
+}
+
+// fakeStore keeps deliveries the way the storage does: every pending one
+// is claimed, whether or not it is due, and recording an attempt moves it
+// on.
+type fakeStore struct {
+	mu         sync.Mutex
+	deliveries map[int64]*domain.WebhookDelivery
+	recorded   []recordedAttempt
+}
+
+func (s *fakeStore) EnqueueWebhookDeliveries(ctx context.Context, event domain.Event) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []domain.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status == domain.DeliveryPending && len(claimed) < limit {
+			claimed = append(claimed, *d)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *fakeStore) RecordWebhookAttempt(ctx context.Context, deliveryID int64, attempt domain.WebhookAttempt, status string, retryIn time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.deliveries[deliveryID]
+	d.Attempts++
+	d.Status = status
+	d.ResponseCode = attempt.ResponseCode
+	d.Log = append(d.Log, attempt)
+	s.recorded = append(s.recorded, recordedAttempt{deliveryID, attempt, status, retryIn})
+	return nil
+}
+
+// redeliver resets a delivery like RedeliverWebhook does.
+func (s *fakeStore) redeliver(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[id].Status = domain.DeliveryPending
+	s.deliveries[id].Attempts = 0
+}
+
+func (s *fakeStore) last() recordedAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recorded[len(s.recorded)-1]
+}
+
+var testWebhooks = config.Webhooks{
+	BatchSize:      10,
+	Timeout:        time.Second,
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     3 * time.Second,
+}
+
+// newTestDeliverer sends with a plain client, since test servers listen on
+// loopback addresses the real one refuses.
+func newTestDeliverer(store Store) *Deliverer {
+	d := NewDeliverer(store, testWebhooks, logger.New("test"))
+	d.client = &http.Client{Timeout: testWebhooks.Timeout}
+	return d
+}
+
+func newTestDelivery(url string) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:        7,
+		EventType: domain.EventOrderCreated,
+		Status:    domain.DeliveryPending,
+		Request: &domain.WebhookRequest{
+			URL:    url,
+			Secret: "0123456789abcdef",
+			Event: domain.Event{
+				ID:            3,
+				AggregateType: domain.AggregateOrder,
+				AggregateID:   5,
+				Type:          domain.EventOrderCreated,
+				Payload:       json.RawMessage(`{"order_id":5}`),
+				CreatedAt:     time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestDelivererRetriesAndRedelivers(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		status   = http.StatusServiceUnavailable
+		requests []*http.Request
+		bodies   [][]byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	delivery := newTestDelivery(srv.URL)
+	store := &fakeStore{deliveries: map[int64]*domain.WebhookDelivery{delivery.ID: delivery}}
+	d := newTestDeliverer(store)
+	ctx := context.Background()
+
+	// Each failure is retried after twice the previous delay, plus up to
+	// 20% jitter, until the attempts run out.
+	retries := []struct {
+		status   string
+		min, max time.Duration
+	}{
+		{domain.DeliveryPending, time.Second, 1200 * time.Millisecond},
+		{domain.DeliveryPending, 2 * time.Second, 2400 * time.Millisecond},
+		{domain.DeliveryFailed, 0, 0},
+	}
+	for i, want := range retries {
+		if _, err := d.deliverBatch(ctx); err != nil {
+			t.Fatal(err)
+		}
+		got := store.last()
+		if got.status != want.status || got.retryIn < want.min || got.retryIn > want.max {
+			t.Errorf("attempt %d: status %s retrying in %s, want %s in [%s, %s]", i+1, got.status, got.retryIn, want.status, want.min, want.max)
+		}
+		if got.attempt.ResponseCode == nil || *got.attempt.ResponseCode != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d: response code %v, want 503", i+1, got.attempt.ResponseCode)
+		}
+		if got.attempt.Error == "" {
+			t.Errorf("attempt %d: no error recorded for a 503", i+1)
+		}
+	}
+
+	// A failed delivery is no longer claimed.
+	if claimed, err := d.deliverBatch(ctx); err != nil || claimed != 0 {
+		t.Fatalf("deliverBatch after giving up = %d, %v; want 0, nil", claimed, err)
+	}
+
+	mu.Lock()
+	status = http.StatusNoContent
+	mu.Unlock()
+	store.redeliver(delivery.ID)
+	if _, err := d.deliverBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := store.last()
+	if got.status != domain.DeliverySucceeded || got.attempt.Error != "" {
+		t.Errorf("redelivery: status %s with error %q, want %s", got.status, got.attempt.Error, domain.DeliverySucceeded)
+	}
+	if got.attempt.ResponseCode == nil || *got.attempt.ResponseCode != http.StatusNoContent {
+		t.Errorf("redelivery: response code %v, want 204", got.attempt.ResponseCode)
+	}
+	if delivery.Attempts != 1 || len(delivery.Log) != 4 {
+		t.Errorf("redelivery: %d attempts with %d logged, want 1 with all 4 logged", delivery.Attempts, len(delivery.Log))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 4 {
+		t.Fatalf("subscriber got %d requests, want 4", len(requests))
+	}
+	r, body := requests[3], bodies[3]
+	if r.Header.Get(HeaderID) != "7" || r.Header.Get(HeaderEvent) != domain.EventOrderCreated {
+		t.Errorf("headers %s=%q %s=%q, want 7 and %s", HeaderID, r.Header.Get(HeaderID), HeaderEvent, r.Header.Get(HeaderEvent), domain.EventOrderCreated)
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("bad %s: %v", HeaderTimestamp, err)
+	}
+	if !Verify("0123456789abcdef", timestamp, body, r.Header.Get(HeaderSignature), time.Now()) {
+		t.Errorf("signature %q does not verify", r.Header.Get(HeaderSignature))
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if envelope.ID != 3 || envelope.AggregateID != 5 || string(envelope.Data) != `{"order_id":5}` {
+		t.Errorf("envelope = %+v", envelope)
+	}
+}
+
+func TestDelivererRecordsUnansweredAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	delivery := newTestDelivery(url)
+	store := &fakeStore{deliveries: map[int64]*domain.WebhookDelivery{delivery.ID: delivery}}
+	if _, err := newTestDeliverer(store).deliverBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := store.last()
+	if got.attempt.ResponseCode != nil || got.attempt.Error == "" || got.status != domain.DeliveryPending {
+		t.Errorf("attempt = %+v with status %s, want an error, no response code and a retry", got.attempt, got.status)
+	}
+}
+
+// drainCheck reports response bodies that are closed before being read to
+// the end, which keeps their connection from being reused.
+type drainCheck struct {
+	t *testing.T
+}
+
+func (c drainCheck) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		resp.Body = &drainedBody{ReadCloser: resp.Body, t: c.t}
+	}
+	return resp, err
+}
+
+type drainedBody struct {
+	io.ReadCloser
+	t   *testing.T
+	eof bool
+}
+
+func (b *drainedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *drainedBody) Close() error {
+	if !b.eof {
+		b.t.Error("response body closed before it was drained")
+	}
+	return b.ReadCloser.Close()
+}
+
+func TestDelivererBacksOffUntilMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(strings.Repeat("error ", 1000)))
+	}))
+	defer srv.Close()
+
+	// Attempts counts earlier attempts, so the delivery gives up on the
+	// attempt that makes MaxAttempts.
+	tests := []struct {
+		attempts int
+		status   string
+		min, max time.Duration
+	}{
+		{0, domain.DeliveryPending, time.Second, 1200 * time.Millisecond},
+		{1, domain.DeliveryPending, 2 * time.Second, 2400 * time.Millisecond},
+		{2, domain.DeliveryFailed, 0, 0},
+		{5, domain.DeliveryFailed, 0, 0},
+	}
+	for _, tt := range tests {
+		delivery := newTestDelivery(srv.URL)
+		delivery.Attempts = tt.attempts
+		store := &fakeStore{deliveries: map[int64]*domain.WebhookDelivery{delivery.ID: delivery}}
+		d := newTestDeliverer(store)
+		d.client.Transport = drainCheck{t}
+		d.deliver(context.Background(), *delivery)
+
+		got := store.last()
+		if got.status != tt.status || got.retryIn < tt.min || got.retryIn > tt.max {
+			t.Errorf("after %d attempts: status %s retrying in %s, want %s in [%s, %s]", tt.attempts, got.status, got.retryIn, tt.status, tt.min, tt.max)
+		}
+		if got.attempt.ResponseCode == nil || *got.attempt.ResponseCode != http.StatusInternalServerError || got.attempt.Error == "" {
+			t.Errorf("after %d attempts: attempt = %+v, want a 500 with an error", tt.attempts, got.attempt)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Envelope is the body POSTed to subscribers.
+type Envelope struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Sign returns the signature header value for a delivery body. The timestamp
+// is part of the signed message so receivers can reject replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// MaxSignatureAge is how far from now a timestamp Verify accepts may be, in
+// either direction to allow for clock skew.
+const MaxSignatureAge = 5 * time.Minute
+
+// Verify reports whether signature was produced by Sign for the same input
+// and timestamp is recent at now, so a captured delivery cannot be replayed
+// later.
+func Verify(secret string, timestamp int64, body []byte, signature string, now time.Time) bool {
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > MaxSignatureAge || age < -MaxSignatureAge {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}